@@ -0,0 +1,226 @@
+// Command sortanalyze studies adversarial inputs found by geneticsort.
+//
+//	sortanalyze complexity [flags]
+//
+// fits how a sort's cost grows on a generated input, to tell O(n²) from a worse constant on O(n·log n).  The input
+// is an evolved program, a seed pattern, or a fixed array (such as a shrunk one) stretched to each size.
+//
+//	sortanalyze trace [flags]
+//
+// sorts one input with an instrumented pdqsort and reports which parts of the sort the input exploits, as text or
+// JSON.
+//
+//	sortanalyze export -dir DIR [flags] FILE...
+//
+// turns saved arrays, such as the winners of several runs, into a go test -fuzz corpus and a regression test in DIR
+// that fails if the target sort, sort.Slice by default like geneticsort's SORT_TARGET, makes more comparisons on any
+// of them.
+//
+//	sortanalyze results -dir DIR [flags]
+//
+// lists the fittest runs recorded to a RESULTS_DIR, or prints the fittest candidate, which the other commands take as
+// -input -.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cep21/geneticsort/internal/arraysort"
+	"github.com/cep21/geneticsort/internal/arraysort/gosort/tracesort"
+	"github.com/cep21/geneticsort/internal/record/filerecord"
+)
+
+func main() {
+	log.SetFlags(0)
+	if len(os.Args) < 2 {
+		log.Fatal("usage: sortanalyze complexity|trace|export|results [flags]")
+	}
+	switch os.Args[1] {
+	case "complexity":
+		complexity(os.Args[2:])
+	case "trace":
+		trace(os.Args[2:])
+	case "export":
+		export(os.Args[2:])
+	case "results":
+		results(os.Args[2:])
+	default:
+		log.Fatalf("unknown command %q: valid commands are complexity, trace, export or results", os.Args[1])
+	}
+}
+
+// source is the input flags every command shares
+type source struct {
+	program *string
+	pattern *string
+	input   *string
+}
+
+func sourceFlags(fs *flag.FlagSet) source {
+	return source{
+		program: fs.String("program", "", "generative program, as printed by a CHROMOSOME=generative run"),
+		pattern: fs.String("pattern", "", "seed pattern name"),
+		input:   fs.String("input", "", "file of comma separated values, or - for stdin"),
+	}
+}
+
+// generator stretches -input to each size
+func (s source) generator(target arraysort.SortTarget) (arraysort.Generator, error) {
+	switch {
+	case *s.program != "" && *s.pattern == "" && *s.input == "":
+		return arraysort.ParseGenerator(*s.program)
+	case *s.pattern != "" && *s.program == "" && *s.input == "":
+		return arraysort.PatternGenerator(*s.pattern, target)
+	case *s.input != "" && *s.program == "" && *s.pattern == "":
+		vals, err := readValues(*s.input)
+		if err != nil {
+			return nil, err
+		}
+		return arraysort.StretchPattern(vals), nil
+	}
+	return nil, fmt.Errorf("exactly one of -program, -pattern or -input is required")
+}
+
+func complexity(args []string) {
+	fs := flag.NewFlagSet("complexity", flag.ExitOnError)
+	target := fs.String("target", "", "sort target to measure")
+	cost := fs.String("cost", "", "cost model to measure")
+	src := sourceFlags(fs)
+	min := fs.Int("min", 256, "smallest n")
+	max := fs.Int("max", 1<<18, "largest n")
+	factor := fs.Float64("factor", 2, "ratio between successive n")
+	mustNil(fs.Parse(args))
+
+	eval, err := arraysort.NewTargetEvaluator(*target, *cost)
+	mustNil(err)
+	generator, err := src.generator(eval.Target)
+	mustNil(err)
+	fmt.Print(arraysort.MeasureComplexity(generator, eval, arraysort.GeometricSizes(*min, *max, *factor)))
+}
+
+func trace(args []string) {
+	fs := flag.NewFlagSet("trace", flag.ExitOnError)
+	src := sourceFlags(fs)
+	n := fs.Int("n", 0, "input size.  Defaults to the size of -input, or 1000 for anything else")
+	asJSON := fs.Bool("json", false, "print the full trace as JSON rather than a summary")
+	mustNil(fs.Parse(args))
+
+	var vals []int
+	if *src.input != "" && *n == 0 {
+		var err error
+		vals, err = readValues(*src.input)
+		mustNil(err)
+	} else {
+		if *n == 0 {
+			*n = 1000
+		}
+		// mcilroy builds against the sort being traced
+		traced, err := arraysort.LookupSortTarget("go1.27/sort.Sort")
+		mustNil(err)
+		generator, err := src.generator(traced)
+		mustNil(err)
+		vals = generator.Generate(*n)
+	}
+	t := tracesort.Sort(sort.IntSlice(vals))
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		mustNil(enc.Encode(t))
+		return
+	}
+	fmt.Print(t)
+}
+
+func export(args []string) {
+	fs := flag.NewFlagSet("export", flag.ExitOnError)
+	dir := fs.String("dir", "", "package directory to write the corpus and sort_regression_test.go into")
+	pkg := fs.String("package", "", "package clause of the test.  Defaults to sortregression")
+	slack := fs.Float64("slack", .1, "fraction more comparisons than measured the test allows")
+	targetName := fs.String("target", "", "sort the inputs were evolved against, and the test checks.  Defaults to sort.Slice, like SORT_TARGET")
+	mustNil(fs.Parse(args))
+	if *dir == "" || fs.NArg() == 0 {
+		log.Fatal("usage: sortanalyze export -dir DIR [flags] FILE...")
+	}
+
+	target, err := arraysort.LookupSortTarget(*targetName)
+	mustNil(err)
+	e := arraysort.RegressionExport{
+		Package: *pkg,
+		Target:  target,
+		Slack:   *slack,
+	}
+	for _, input := range fs.Args() {
+		vals, err := readValues(input)
+		mustNil(err)
+		e.Add(vals)
+	}
+	mustNil(os.MkdirAll(*dir, 0755))
+	mustNil(e.WriteCorpus(*dir))
+	mustNil(e.WriteTest(filepath.Join(*dir, "sort_regression_test.go")))
+}
+
+func results(args []string) {
+	fs := flag.NewFlagSet("results", flag.ExitOnError)
+	dir := fs.String("dir", "", "RESULTS_DIR of the runs")
+	family := fs.String("family", "", "only list this family")
+	top := fs.Int("top", 10, "how many runs to list, or every run if 0 or less")
+	candidate := fs.Bool("candidate", false, "print the fittest candidate rather than a list")
+	mustNil(fs.Parse(args))
+	if *dir == "" {
+		log.Fatal("usage: sortanalyze results -dir DIR [flags]")
+	}
+
+	store := &filerecord.Recorder{Dir: *dir}
+	entries, err := store.Top(*family, *top)
+	mustNil(err)
+	if *candidate {
+		if len(entries) == 0 {
+			log.Fatal("no runs recorded")
+		}
+		best, err := store.Candidate(entries[0])
+		mustNil(err)
+		fmt.Println(best)
+		return
+	}
+	for _, e := range entries {
+		fmt.Printf("%d\t%s\t%s\tseed=%d\tstart=%s\tcommit=%s\n", e.Fitness, e.Family, e.Key, e.Manifest.Seed, e.Manifest.Start.Format(time.RFC3339), e.Manifest.Commit)
+	}
+}
+
+func readValues(input string) ([]int, error) {
+	var b []byte
+	var err error
+	if input == "-" {
+		b, err = io.ReadAll(os.Stdin)
+	} else {
+		b, err = os.ReadFile(input)
+	}
+	if err != nil {
+		return nil, err
+	}
+	var ret []int
+	for _, field := range strings.Split(strings.TrimSpace(string(b)), ",") {
+		v, err := strconv.Atoi(strings.TrimSpace(field))
+		if err != nil {
+			return nil, err
+		}
+		ret = append(ret, v)
+	}
+	return ret, nil
+}
+
+func mustNil(err error) {
+	if err != nil {
+		log.Fatal(err)
+	}
+}
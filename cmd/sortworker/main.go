@@ -0,0 +1,31 @@
+// Command sortworker is a worker for arraysort.ExternalEvaluator that scores arrays with one of the built in sort
+// targets.  It is the reference for writing workers in other languages.
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+
+	"github.com/cep21/geneticsort/internal/arraysort"
+)
+
+func main() {
+	target := flag.String("target", "", "sort target to score with")
+	cost := flag.String("cost", "", "cost model to score with")
+	flag.Parse()
+	t, err := arraysort.LookupSortTarget(*target)
+	if err != nil {
+		log.Fatal(err)
+	}
+	c, err := arraysort.LookupCostModel(*cost)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := arraysort.CheckCostModel(t, c); err != nil {
+		log.Fatal(err)
+	}
+	if err := arraysort.ServeSortWorker(os.Stdin, os.Stdout, t, c); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -2,6 +2,7 @@ package genetic
 
 import (
 	"log"
+	"time"
 )
 
 type Algorithm struct {
@@ -15,38 +16,70 @@ type Algorithm struct {
 	Mutator           Mutation
 	NumberOfParents   int
 	PopulationSize    int
-	NumGoroutine      int
+	// OffspringSize is how many children are bred each generation (λ).  Defaults to PopulationSize.
+	OffspringSize int
+	NumGoroutine  int
+	// Stats of the most recent generation of Run
+	Stats Stats
+	// Manifest of the last Run.  Set Seed and Config before calling Run.
+	Manifest Manifest
+}
+
+func (a *Algorithm) offspringSize() int {
+	if a.OffspringSize == 0 {
+		return a.PopulationSize
+	}
+	return a.OffspringSize
+}
+
+func (a *Algorithm) survivorSelection() SurvivorSelection {
+	if a.SurvivorSelection == nil {
+		return &GenerationalSurvivorSelection{}
+	}
+	return a.SurvivorSelection
 }
 
 func (a *Algorithm) Run() Chromosome {
-	currentPopulation := SpawnPopulation(a.PopulationSize, a.Factory, a.RandForIndex.Rand(0))
+	start := time.Now()
+	currentPopulation := SpawnPopulation(a.PopulationSize, a.Factory, a.RandForIndex)
+	evaluations := currentPopulation.calculateFitness(a.NumGoroutine)
 	best := currentPopulation.Max()
 	asDynamic, isDynamic := a.Mutator.(DynamicMutation)
 	if isDynamic {
-		asDynamic.ResetMutationRate(a.RandForIndex.Rand(0))
+		asDynamic.ResetMutationRate(a.RandForIndex.Rand(0, 0, PurposeInitialMutationRate))
 	}
 	runCounter := 0
 	for {
+		a.Stats = computeStats(&currentPopulation)
+		a.Stats.Generation = runCounter
+		a.Stats.Evaluations = evaluations
+		a.Stats.Elapsed = time.Since(start)
+		stats := a.Stats
+		currentPopulation.stats = &stats
 		if a.Log != nil {
-			a.Log.Println("Index/mean/max", runCounter, currentPopulation.Average(), currentPopulation.Max().Fitness())
+			a.Log.Println("Index/mean/max/stddev/diversity", runCounter, a.Stats.Mean, a.Stats.Best, a.Stats.StdDev, a.Stats.Diversity)
 		}
+		generation := runCounter
 		runCounter++
-		if a.Terminator.StopExecution(currentPopulation, a.RandForIndex.Rand(0)) {
+		if a.Terminator.StopExecution(currentPopulation, a.RandForIndex.Rand(generation, 0, PurposeTermination)) {
 			if asSimpl, canSimpl := best.(Simplifyable); canSimpl {
 				asSimpl.Simplify()
 			}
+			a.Manifest.collect(start, time.Now(), a.Stats)
 			return best
 		}
-		nextPopulation := currentPopulation.NextGeneration(a.ParentSelector, a.Crossover, a.Mutator, a.NumberOfParents, a.NumGoroutine, a.RandForIndex)
-		nextPopulation = a.SurvivorSelection.NextGeneration(&currentPopulation, &nextPopulation, a.RandForIndex.Rand(0))
+		nextPopulation := currentPopulation.NextGeneration(a.ParentSelector, a.Crossover, a.Mutator, a.NumberOfParents, a.offspringSize(), a.NumGoroutine, generation, a.RandForIndex)
+		// Evaluate offspring in parallel now, rather than one at a time inside survivor selection
+		evaluations += nextPopulation.calculateFitness(a.NumGoroutine)
+		nextPopulation = a.survivorSelection().NextGeneration(&currentPopulation, &nextPopulation, a.RandForIndex.Rand(generation, 0, PurposeSurvivorSelection))
 		nextBest := nextPopulation.Max()
 		if best.Fitness() < nextBest.Fitness() {
 			best = nextPopulation.Max()
 			if isDynamic {
-				asDynamic.ResetMutationRate(a.RandForIndex.Rand(0))
+				asDynamic.ResetMutationRate(a.RandForIndex.Rand(generation, 0, PurposeMutationRate))
 			}
 		} else if isDynamic {
-			asDynamic.IncreaseMutationRate(a.RandForIndex.Rand(0))
+			asDynamic.IncreaseMutationRate(a.RandForIndex.Rand(generation, 0, PurposeMutationRate))
 		}
 		currentPopulation = nextPopulation
 	}
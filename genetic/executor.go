@@ -32,24 +32,82 @@ type MultiTermination struct {
 	Executors []Termination
 }
 
+// String is the equivalent OR expression, since "multi-" can't hold children that are expressions themselves
 func (c *MultiTermination) String() string {
-	parts := make([]string, 0, len(c.Executors))
-	for _, e := range c.Executors {
-		parts = append(parts, e.String())
-	}
-	return fmt.Sprintf("multi-%s", strings.Join(parts, ","))
+	return joinTerminations(c.Executors, "|")
 }
 
 func (c *MultiTermination) StopExecution(p Population, r Rand) bool {
 	shouldStop := false
 	for _, e := range c.Executors {
-		shouldStop = shouldStop || e.StopExecution(p, r)
+		// Always call StopExecution, so every child sees every generation
+		shouldStop = e.StopExecution(p, r) || shouldStop
 	}
 	return shouldStop
 }
 
 var _ Termination = &MultiTermination{}
 
+func joinTerminations(t []Termination, op string) string {
+	parts := make([]string, 0, len(t))
+	for _, e := range t {
+		parts = append(parts, e.String())
+	}
+	return "(" + strings.Join(parts, op) + ")"
+}
+
+// AnyTermination stops when any of Terminations wants to stop.  Every child is evaluated each generation.
+type AnyTermination struct {
+	Terminations []Termination
+}
+
+func (c *AnyTermination) String() string {
+	return joinTerminations(c.Terminations, "|")
+}
+
+func (c *AnyTermination) StopExecution(p Population, r Rand) bool {
+	shouldStop := false
+	for _, e := range c.Terminations {
+		shouldStop = e.StopExecution(p, r) || shouldStop
+	}
+	return shouldStop
+}
+
+var _ Termination = &AnyTermination{}
+
+// AllTermination stops only when all of Terminations want to stop.  Every child is evaluated each generation.
+type AllTermination struct {
+	Terminations []Termination
+}
+
+func (c *AllTermination) String() string {
+	return joinTerminations(c.Terminations, "&")
+}
+
+func (c *AllTermination) StopExecution(p Population, r Rand) bool {
+	shouldStop := len(c.Terminations) > 0
+	for _, e := range c.Terminations {
+		shouldStop = e.StopExecution(p, r) && shouldStop
+	}
+	return shouldStop
+}
+
+var _ Termination = &AllTermination{}
+
+type NotTermination struct {
+	Termination Termination
+}
+
+func (c *NotTermination) String() string {
+	return "!" + c.Termination.String()
+}
+
+func (c *NotTermination) StopExecution(p Population, r Rand) bool {
+	return !c.Termination.StopExecution(p, r)
+}
+
+var _ Termination = &NotTermination{}
+
 type NoImprovementTermination struct {
 	Consecutive        int
 	currentBest        int
@@ -61,7 +119,7 @@ func (c *NoImprovementTermination) String() string {
 }
 
 func (c *NoImprovementTermination) StopExecution(p Population, _ Rand) bool {
-	best := p.Max().Fitness()
+	best := p.Stats().Best
 	if best > c.currentBest {
 		c.currentBest = best
 		c.currentConsecutive = 0
@@ -104,3 +162,88 @@ func (c *TimingTermination) StopExecution(p Population, _ Rand) bool {
 }
 
 var _ Termination = &TimingTermination{}
+
+// TargetFitnessTermination stops as soon as any individual reaches Target fitness
+type TargetFitnessTermination struct {
+	Target int
+}
+
+func (t *TargetFitnessTermination) String() string {
+	return fmt.Sprintf("target-%d", t.Target)
+}
+
+func (t *TargetFitnessTermination) StopExecution(p Population, _ Rand) bool {
+	return p.Stats().Best >= t.Target
+}
+
+var _ Termination = &TargetFitnessTermination{}
+
+// EvaluationBudgetTermination stops once Budget fitness computations have been done, so runs can be compared by work
+// rather than time.  Cached fitness values are not counted.
+type EvaluationBudgetTermination struct {
+	Budget int64
+}
+
+func (e *EvaluationBudgetTermination) String() string {
+	return fmt.Sprintf("evaluations-%d", e.Budget)
+}
+
+func (e *EvaluationBudgetTermination) StopExecution(p Population, _ Rand) bool {
+	return p.Stats().Evaluations >= e.Budget
+}
+
+var _ Termination = &EvaluationBudgetTermination{}
+
+// StdDevTermination stops once the standard deviation of fitness drops to Threshold
+type StdDevTermination struct {
+	Threshold float64
+}
+
+func (s *StdDevTermination) String() string {
+	return fmt.Sprintf("stddev-%g", s.Threshold)
+}
+
+func (s *StdDevTermination) StopExecution(p Population, _ Rand) bool {
+	return p.Stats().StdDev <= s.Threshold
+}
+
+var _ Termination = &StdDevTermination{}
+
+// MeanConvergenceTermination stops once mean fitness has been within Epsilon of the best fitness for Consecutive
+// generations in a row
+type MeanConvergenceTermination struct {
+	Epsilon            float64
+	Consecutive        int
+	currentConsecutive int
+}
+
+func (m *MeanConvergenceTermination) String() string {
+	return fmt.Sprintf("meanmax-%g-%d", m.Epsilon, m.Consecutive)
+}
+
+func (m *MeanConvergenceTermination) StopExecution(p Population, _ Rand) bool {
+	stats := p.Stats()
+	if float64(stats.Best)-stats.Mean > m.Epsilon {
+		m.currentConsecutive = 0
+		return false
+	}
+	m.currentConsecutive++
+	return m.currentConsecutive >= m.Consecutive
+}
+
+var _ Termination = &MeanConvergenceTermination{}
+
+// DiversityTermination stops once the fraction of distinct genotypes in the population drops below Floor
+type DiversityTermination struct {
+	Floor float64
+}
+
+func (d *DiversityTermination) String() string {
+	return fmt.Sprintf("diversity-%g", d.Floor)
+}
+
+func (d *DiversityTermination) StopExecution(p Population, _ Rand) bool {
+	return p.Stats().Diversity < d.Floor
+}
+
+var _ Termination = &DiversityTermination{}
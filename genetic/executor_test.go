@@ -0,0 +1,126 @@
+package genetic
+
+import (
+	"testing"
+)
+
+func TestParseTermination(t *testing.T) {
+	cases := map[string]string{
+		"timing-10m&(consecutive-50|target-40000)": "(timing-10m0s&(consecutive-50|target-40000))",
+		"counting-3": "counting-3",
+		"stddev-0.5|meanmax-1.5-20|diversity-0.1": "(stddev-0.5|meanmax-1.5-20|diversity-0.1)",
+		"!evaluations-100 | counting-5":           "(!evaluations-100|counting-5)",
+		"a-1":                                     "",
+		"multi-timing-1m0s,consecutive-5":         "(timing-1m0s|consecutive-5)",
+		"(counting-1":                             "",
+		"counting-x":                              "",
+		"counting-1&":                             "",
+	}
+	for in, expected := range cases {
+		parsed, err := ParseTermination(in)
+		if expected == "" {
+			if err == nil {
+				t.Errorf("expected %q to fail, got %s", in, parsed)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("unable to parse %q: %v", in, err)
+			continue
+		}
+		if parsed.String() != expected {
+			t.Errorf("parsed %q as %q, expected %q", in, parsed.String(), expected)
+		}
+		if again, err := ParseTermination(parsed.String()); err != nil || again.String() != expected {
+			t.Errorf("%q does not round trip: %v", parsed.String(), err)
+		}
+	}
+}
+
+func TestMultiTerminationRoundTrip(t *testing.T) {
+	m := &MultiTermination{Executors: []Termination{
+		&AllTermination{Terminations: []Termination{&CountingTermination{Limit: 3}, &TargetFitnessTermination{Target: 10}}},
+		&NotTermination{Termination: &StdDevTermination{Threshold: .5}},
+	}}
+	parsed, err := ParseTermination(m.String())
+	if err != nil {
+		t.Fatalf("unable to parse %q: %v", m, err)
+	}
+	if parsed.String() != m.String() {
+		t.Errorf("%q parsed back as %q", m, parsed)
+	}
+}
+
+func TestAnyTerminationEvaluatesEveryChild(t *testing.T) {
+	first := &CountingTermination{Limit: 0}
+	second := &CountingTermination{Limit: 10}
+	anyTerm := &AnyTermination{Terminations: []Termination{first, second}}
+	for i := 0; i < 3; i++ {
+		if !anyTerm.StopExecution(Population{}, nil) {
+			t.Fatal("expected any to stop")
+		}
+	}
+	if second.i != 3 {
+		t.Errorf("expected every child to be called, but second was called %d times", second.i)
+	}
+	allTerm := &AllTermination{Terminations: []Termination{&NotTermination{Termination: first}, second}}
+	if allTerm.StopExecution(Population{}, nil) {
+		t.Error("expected all to keep going")
+	}
+	if second.i != 4 {
+		t.Errorf("expected every child to be called, but second was called %d times", second.i)
+	}
+}
+
+func TestTargetAndBudgetTerminations(t *testing.T) {
+	p := Population{Individuals: individuals(3, 9, 4)}
+	if (&TargetFitnessTermination{Target: 10}).StopExecution(p, nil) {
+		t.Error("stopped below the target")
+	}
+	if !(&TargetFitnessTermination{Target: 9}).StopExecution(p, nil) {
+		t.Error("didn't stop at the target")
+	}
+	// Evaluations only come from Algorithm's stats
+	p.stats = &Stats{Evaluations: 99}
+	if (&EvaluationBudgetTermination{Budget: 100}).StopExecution(p, nil) {
+		t.Error("stopped under budget")
+	}
+	p.stats.Evaluations = 100
+	if !(&EvaluationBudgetTermination{Budget: 100}).StopExecution(p, nil) {
+		t.Error("didn't stop at the budget")
+	}
+}
+
+func TestConvergenceTerminations(t *testing.T) {
+	spread := Population{Individuals: individuals(0, 10, 0, 10)}
+	same := Population{Individuals: individuals(5, 5, 5, 5)}
+	stddev := &StdDevTermination{Threshold: 1}
+	if stddev.StopExecution(spread, nil) || !stddev.StopExecution(same, nil) {
+		t.Error("stddev should stop only once fitness stops varying")
+	}
+
+	// Mean is 5 below the best for spread, and equal for same
+	meanMax := &MeanConvergenceTermination{Epsilon: 1, Consecutive: 2}
+	for i, want := range []bool{false, true, true, false, false, true} {
+		p := same
+		if i == 3 {
+			p = spread
+		}
+		if got := meanMax.StopExecution(p, nil); got != want {
+			t.Errorf("meanmax call %d: got %v, want %v", i, got, want)
+		}
+	}
+
+	// same has a single genotype out of 4, spread has 2
+	diversity := &DiversityTermination{Floor: .4}
+	if diversity.StopExecution(spread, nil) || !diversity.StopExecution(same, nil) {
+		t.Error("diversity should stop only once genotypes stop varying")
+	}
+	single := Population{Individuals: individuals(7)}
+	if !stddev.StopExecution(single, nil) || diversity.StopExecution(single, nil) {
+		t.Error("a population of 1 has no spread, but is fully diverse")
+	}
+	if stats := computeStats(&Population{}); stats != (Stats{}) {
+		t.Errorf("an empty population has stats %+v", stats)
+	}
+}
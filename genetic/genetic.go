@@ -11,6 +11,22 @@ type LocalOptimization interface {
 	LocallyOptimize() Chromosome
 }
 
+// CachedFitness is implemented by chromosomes that remember their fitness, so runs can count how many fitness
+// computations they actually did.
+type CachedFitness interface {
+	FitnessCached() bool
+}
+
+// Genotype is implemented by chromosomes that can hash their genes more cheaply than hashing String()
+type Genotype interface {
+	GenotypeHash() uint64
+}
+
+// Measurable is implemented by chromosomes that can report other measurements besides fitness
+type Measurable interface {
+	Metrics() map[string]int
+}
+
 type Simplifyable interface {
 	Simplify()
 }
@@ -0,0 +1,65 @@
+package genetic
+
+import (
+	"os"
+	"runtime"
+	"runtime/debug"
+	"time"
+)
+
+// Manifest is what it takes to reproduce a run and judge its result.  Run fills in everything but Seed and Config,
+// which only the caller knows.
+type Manifest struct {
+	// Seed is what RandForIndex was derived from
+	Seed int64 `json:"seed"`
+	// Config is the caller's settings, by name
+	Config map[string]string `json:"config,omitempty"`
+	Start  time.Time         `json:"start"`
+	End    time.Time         `json:"end"`
+	// Stats of the last generation, which include the generation and evaluation counts
+	Stats     Stats  `json:"stats"`
+	GoVersion string `json:"go_version"`
+	GOOS      string `json:"goos"`
+	GOARCH    string `json:"goarch"`
+	NumCPU    int    `json:"num_cpu"`
+	Host      string `json:"host"`
+	// Commit is the vcs revision the binary was built from, if go build recorded one.  Callers that build without
+	// vcs information, such as from a docker context, should set it themselves.
+	Commit string `json:"commit,omitempty"`
+}
+
+// WallTime is how long the run took
+func (m *Manifest) WallTime() time.Duration {
+	return m.End.Sub(m.Start)
+}
+
+func (m *Manifest) collect(start time.Time, end time.Time, stats Stats) {
+	m.Start, m.End, m.Stats = start, end, stats
+	m.GoVersion, m.GOOS, m.GOARCH, m.NumCPU = runtime.Version(), runtime.GOOS, runtime.GOARCH, runtime.NumCPU()
+	if host, err := os.Hostname(); err == nil {
+		m.Host = host
+	}
+	if m.Commit == "" {
+		m.Commit = buildCommit()
+	}
+}
+
+func buildCommit() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return ""
+	}
+	var revision, modified string
+	for _, s := range info.Settings {
+		switch s.Key {
+		case "vcs.revision":
+			revision = s.Value
+		case "vcs.modified":
+			modified = s.Value
+		}
+	}
+	if revision != "" && modified == "true" {
+		return revision + "-dirty"
+	}
+	return revision
+}
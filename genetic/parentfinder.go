@@ -3,6 +3,7 @@ package genetic
 import (
 	"fmt"
 	"math"
+	"sort"
 )
 
 type ParentSelector interface {
@@ -34,3 +35,415 @@ func (s TournamentParentSelector) PickParent(c []Chromosome, r Rand) int {
 }
 
 var _ ParentSelector = &TournamentParentSelector{}
+
+// selectionTable is an immutable, pre-computed distribution over a population.  Once built it is safe to share
+// between goroutines, and each pick is O(log n).
+type selectionTable struct {
+	population []Chromosome
+	// cumulative[i] is the total weight of slots [0, i].  If nil, every slot has equal weight.
+	cumulative []float64
+	// indexes maps a slot to an index into population.  If nil, slot i is population index i.
+	indexes []int
+}
+
+func (t *selectionTable) pick(r Rand) int {
+	var slot int
+	if t.cumulative == nil {
+		n := len(t.population)
+		if t.indexes != nil {
+			n = len(t.indexes)
+		}
+		slot = r.Intn(n)
+	} else {
+		x := r.Float64() * t.cumulative[len(t.cumulative)-1]
+		slot = sort.Search(len(t.cumulative), func(i int) bool {
+			return t.cumulative[i] > x
+		})
+		if slot == len(t.cumulative) {
+			slot--
+		}
+	}
+	if t.indexes != nil {
+		return t.indexes[slot]
+	}
+	return slot
+}
+
+func (t *selectionTable) isFor(c []Chromosome) bool {
+	if len(t.population) != len(c) {
+		return false
+	}
+	return len(c) == 0 || (&t.population[0] == &c[0] && t.population[len(c)-1] == c[len(c)-1])
+}
+
+func cumulativeWeights(weights []float64) []float64 {
+	total := 0.0
+	ret := make([]float64, len(weights))
+	for i, w := range weights {
+		total += w
+		ret[i] = total
+	}
+	if total <= 0 || math.IsInf(total, 0) || math.IsNaN(total) {
+		// Degenerate weights: fall back to a uniform pick
+		return nil
+	}
+	return ret
+}
+
+// rankedIndexes returns the indexes of c ordered from least to most fit
+func rankedIndexes(c []Chromosome) []int {
+	ret := make([]int, len(c))
+	for i := range ret {
+		ret[i] = i
+	}
+	sort.SliceStable(ret, func(i, j int) bool {
+		return c[ret[i]].Fitness() < c[ret[j]].Fitness()
+	})
+	return ret
+}
+
+// PreparableParentSelector is a ParentSelector that needs to look at the whole population before picking.  Prepare
+// is called once per generation, before breeding, and returns an immutable ParentSelector for that population that
+// every breeding goroutine can share.  Calling PickParent without Prepare still works, but rebuilds that state on
+// every call, as if for generation 0.
+type PreparableParentSelector interface {
+	ParentSelector
+	Prepare(population []Chromosome, generation int, r Rand) ParentSelector
+}
+
+// PrepareParentSelector returns ps prepared for population, or ps itself if it does not need preparing
+func PrepareParentSelector(ps ParentSelector, population []Chromosome, generation int, r Rand) ParentSelector {
+	if asPreparable, ok := ps.(PreparableParentSelector); ok {
+		return asPreparable.Prepare(population, generation, r)
+	}
+	return ps
+}
+
+type preparedParentSelector struct {
+	name  string
+	table *selectionTable
+}
+
+func (p *preparedParentSelector) String() string {
+	return p.name
+}
+
+func (p *preparedParentSelector) PickParent(c []Chromosome, r Rand) int {
+	if !p.table.isFor(c) {
+		panic("parent selector was prepared for a different population")
+	}
+	return p.table.pick(r)
+}
+
+var _ ParentSelector = &preparedParentSelector{}
+
+// RouletteParentSelector picks parents with probability proportional to their fitness
+type RouletteParentSelector struct {
+}
+
+func (s *RouletteParentSelector) String() string {
+	return "roulette"
+}
+
+func (s *RouletteParentSelector) PickParent(c []Chromosome, r Rand) int {
+	return s.Prepare(c, 0, r).PickParent(c, r)
+}
+
+func (s *RouletteParentSelector) Prepare(c []Chromosome, _ int, r Rand) ParentSelector {
+	return &preparedParentSelector{
+		name:  s.String(),
+		table: buildRouletteTable(c, r),
+	}
+}
+
+func buildRouletteTable(c []Chromosome, _ Rand) *selectionTable {
+	minFitness := 0
+	for _, x := range c {
+		if x.Fitness() < minFitness {
+			minFitness = x.Fitness()
+		}
+	}
+	weights := make([]float64, len(c))
+	for i, x := range c {
+		// Shift negative fitness so every weight is positive
+		weights[i] = float64(x.Fitness() - minFitness)
+	}
+	return &selectionTable{
+		population: c,
+		cumulative: cumulativeWeights(weights),
+	}
+}
+
+var _ ParentSelector = &RouletteParentSelector{}
+var _ PreparableParentSelector = &RouletteParentSelector{}
+
+// StochasticUniversalParentSelector spins a fitness proportionate wheel once per generation with len(population)
+// evenly spaced pointers, then hands out the selected parents uniformly.
+type StochasticUniversalParentSelector struct {
+}
+
+func (s *StochasticUniversalParentSelector) String() string {
+	return "sus"
+}
+
+func (s *StochasticUniversalParentSelector) PickParent(c []Chromosome, r Rand) int {
+	return s.Prepare(c, 0, r).PickParent(c, r)
+}
+
+func (s *StochasticUniversalParentSelector) Prepare(c []Chromosome, _ int, r Rand) ParentSelector {
+	return &preparedParentSelector{
+		name:  s.String(),
+		table: buildSUSTable(c, r),
+	}
+}
+
+func buildSUSTable(c []Chromosome, r Rand) *selectionTable {
+	wheel := buildRouletteTable(c, r)
+	if wheel.cumulative == nil {
+		return wheel
+	}
+	total := wheel.cumulative[len(wheel.cumulative)-1]
+	step := total / float64(len(c))
+	pointer := r.Float64() * step
+	selected := make([]int, 0, len(c))
+	slot := 0
+	for i := 0; i < len(c); i++ {
+		for slot < len(wheel.cumulative)-1 && wheel.cumulative[slot] <= pointer {
+			slot++
+		}
+		selected = append(selected, slot)
+		pointer += step
+	}
+	return &selectionTable{
+		population: c,
+		indexes:    selected,
+	}
+}
+
+var _ ParentSelector = &StochasticUniversalParentSelector{}
+var _ PreparableParentSelector = &StochasticUniversalParentSelector{}
+
+// LinearRankParentSelector picks parents by their rank, not their raw fitness.  Pressure is in [1, 2] and is the
+// expected number of times the best individual is picked per len(population) picks.  Defaults to 1.5.
+type LinearRankParentSelector struct {
+	Pressure float64
+}
+
+func (s *LinearRankParentSelector) pressure() float64 {
+	if s.Pressure == 0 {
+		return 1.5
+	}
+	return s.Pressure
+}
+
+func (s *LinearRankParentSelector) String() string {
+	return fmt.Sprintf("linear-rank-%.2f", s.pressure())
+}
+
+func (s *LinearRankParentSelector) PickParent(c []Chromosome, r Rand) int {
+	return s.Prepare(c, 0, r).PickParent(c, r)
+}
+
+func (s *LinearRankParentSelector) Prepare(c []Chromosome, _ int, r Rand) ParentSelector {
+	return &preparedParentSelector{
+		name:  s.String(),
+		table: s.buildTable(c, r),
+	}
+}
+
+func (s *LinearRankParentSelector) buildTable(c []Chromosome, _ Rand) *selectionTable {
+	sp := s.pressure()
+	n := len(c)
+	weights := make([]float64, n)
+	for rank := range weights {
+		weights[rank] = 2 - sp
+		if n > 1 {
+			weights[rank] += 2 * float64(rank) * (sp - 1) / float64(n-1)
+		}
+	}
+	return &selectionTable{
+		population: c,
+		cumulative: cumulativeWeights(weights),
+		indexes:    rankedIndexes(c),
+	}
+}
+
+var _ ParentSelector = &LinearRankParentSelector{}
+var _ PreparableParentSelector = &LinearRankParentSelector{}
+
+// ExponentialRankParentSelector weights the i-th best individual by Base^i.  Base is in (0, 1) and defaults to .99
+type ExponentialRankParentSelector struct {
+	Base float64
+}
+
+func (s *ExponentialRankParentSelector) base() float64 {
+	if s.Base == 0 {
+		return .99
+	}
+	return s.Base
+}
+
+func (s *ExponentialRankParentSelector) String() string {
+	return fmt.Sprintf("exp-rank-%.3f", s.base())
+}
+
+func (s *ExponentialRankParentSelector) PickParent(c []Chromosome, r Rand) int {
+	return s.Prepare(c, 0, r).PickParent(c, r)
+}
+
+func (s *ExponentialRankParentSelector) Prepare(c []Chromosome, _ int, r Rand) ParentSelector {
+	return &preparedParentSelector{
+		name:  s.String(),
+		table: s.buildTable(c, r),
+	}
+}
+
+func (s *ExponentialRankParentSelector) buildTable(c []Chromosome, _ Rand) *selectionTable {
+	n := len(c)
+	weights := make([]float64, n)
+	for rank := range weights {
+		weights[rank] = math.Pow(s.base(), float64(n-1-rank))
+	}
+	return &selectionTable{
+		population: c,
+		cumulative: cumulativeWeights(weights),
+		indexes:    rankedIndexes(c),
+	}
+}
+
+var _ ParentSelector = &ExponentialRankParentSelector{}
+var _ PreparableParentSelector = &ExponentialRankParentSelector{}
+
+// TemperatureSchedule gives the Boltzmann temperature of a generation
+type TemperatureSchedule interface {
+	Temperature(generation int) float64
+	String() string
+}
+
+// ExponentialCooling starts at Initial and multiplies by Alpha each generation, never going below Minimum
+type ExponentialCooling struct {
+	Initial float64
+	Alpha   float64
+	Minimum float64
+}
+
+func (e *ExponentialCooling) String() string {
+	return fmt.Sprintf("expcool-%g-%g-%g", e.Initial, e.Alpha, e.Minimum)
+}
+
+func (e *ExponentialCooling) Temperature(generation int) float64 {
+	return math.Max(e.Initial*math.Pow(e.Alpha, float64(generation)), e.Minimum)
+}
+
+// LinearCooling starts at Initial and subtracts Step each generation, never going below Minimum
+type LinearCooling struct {
+	Initial float64
+	Step    float64
+	Minimum float64
+}
+
+func (l *LinearCooling) String() string {
+	return fmt.Sprintf("lincool-%g-%g-%g", l.Initial, l.Step, l.Minimum)
+}
+
+func (l *LinearCooling) Temperature(generation int) float64 {
+	return math.Max(l.Initial-l.Step*float64(generation), l.Minimum)
+}
+
+var _ TemperatureSchedule = &ExponentialCooling{}
+var _ TemperatureSchedule = &LinearCooling{}
+
+// BoltzmannParentSelector weights each individual by exp(fitness/T).  T comes from Schedule for the generation being
+// prepared, so early generations explore and later ones exploit.  At T <= 0 it only picks the fittest.
+type BoltzmannParentSelector struct {
+	Schedule TemperatureSchedule
+}
+
+func (s *BoltzmannParentSelector) String() string {
+	return fmt.Sprintf("boltzmann-%s", s.Schedule.String())
+}
+
+func (s *BoltzmannParentSelector) PickParent(c []Chromosome, r Rand) int {
+	return s.buildTable(c, 0).pick(r)
+}
+
+func (s *BoltzmannParentSelector) Prepare(c []Chromosome, generation int, _ Rand) ParentSelector {
+	return &preparedParentSelector{
+		name:  s.String(),
+		table: s.buildTable(c, generation),
+	}
+}
+
+func (s *BoltzmannParentSelector) buildTable(c []Chromosome, generation int) *selectionTable {
+	temperature := s.Schedule.Temperature(generation)
+	maxFitness := math.MinInt64
+	for _, x := range c {
+		if x.Fitness() > maxFitness {
+			maxFitness = x.Fitness()
+		}
+	}
+	weights := make([]float64, len(c))
+	for i, x := range c {
+		if temperature <= 0 {
+			// The limit as T goes to 0, rather than the NaN of 0/0
+			if x.Fitness() == maxFitness {
+				weights[i] = 1
+			}
+			continue
+		}
+		// Subtracting the max keeps exp() from overflowing
+		weights[i] = math.Exp(float64(x.Fitness()-maxFitness) / temperature)
+	}
+	return &selectionTable{
+		population: c,
+		cumulative: cumulativeWeights(weights),
+	}
+}
+
+var _ ParentSelector = &BoltzmannParentSelector{}
+var _ PreparableParentSelector = &BoltzmannParentSelector{}
+
+// TruncationParentSelector picks uniformly among the top Fraction of the population.  Fraction defaults to .5
+type TruncationParentSelector struct {
+	Fraction float64
+}
+
+func (s *TruncationParentSelector) fraction() float64 {
+	if s.Fraction == 0 {
+		return .5
+	}
+	return s.Fraction
+}
+
+func (s *TruncationParentSelector) String() string {
+	return fmt.Sprintf("truncation-%.2f", s.fraction())
+}
+
+func (s *TruncationParentSelector) PickParent(c []Chromosome, r Rand) int {
+	return s.Prepare(c, 0, r).PickParent(c, r)
+}
+
+func (s *TruncationParentSelector) Prepare(c []Chromosome, _ int, r Rand) ParentSelector {
+	return &preparedParentSelector{
+		name:  s.String(),
+		table: s.buildTable(c, r),
+	}
+}
+
+func (s *TruncationParentSelector) buildTable(c []Chromosome, _ Rand) *selectionTable {
+	ranked := rankedIndexes(c)
+	keep := int(math.Ceil(s.fraction() * float64(len(c))))
+	if keep < 1 {
+		keep = 1
+	}
+	if keep > len(ranked) {
+		keep = len(ranked)
+	}
+	return &selectionTable{
+		population: c,
+		indexes:    ranked[len(ranked)-keep:],
+	}
+}
+
+var _ ParentSelector = &TruncationParentSelector{}
+var _ PreparableParentSelector = &TruncationParentSelector{}
@@ -0,0 +1,129 @@
+package genetic
+
+import (
+	"math"
+	"math/rand"
+	"strconv"
+	"testing"
+)
+
+// fitness is a chromosome that is only its fitness
+type fitness int
+
+func (f fitness) Fitness() int      { return int(f) }
+func (f fitness) Clone() Chromosome { return f }
+func (f fitness) Shell() Chromosome { return f }
+func (f fitness) String() string    { return strconv.Itoa(int(f)) }
+
+func individuals(fitnesses ...int) []Chromosome {
+	ret := make([]Chromosome, len(fitnesses))
+	for i, f := range fitnesses {
+		ret[i] = fitness(f)
+	}
+	return ret
+}
+
+const picks = 100000
+
+// pickShares prepares ps for c, as of generation, and returns the share of picks each individual got
+func pickShares(ps ParentSelector, c []Chromosome, generation int) []float64 {
+	r := rand.New(rand.NewSource(1))
+	ps = PrepareParentSelector(ps, c, generation, r)
+	ret := make([]float64, len(c))
+	for i := 0; i < picks; i++ {
+		ret[ps.PickParent(c, r)] += 1.0 / picks
+	}
+	return ret
+}
+
+func checkShares(t *testing.T, name string, got []float64, want ...float64) {
+	t.Helper()
+	for i := range want {
+		if math.Abs(got[i]-want[i]) > .01 {
+			t.Errorf("%s: got shares %.3f, want %.3f", name, got, want)
+			return
+		}
+	}
+}
+
+func TestParentSelectorDistributions(t *testing.T) {
+	checkShares(t, "roulette", pickShares(&RouletteParentSelector{}, individuals(0, 1, 3), 0), 0, .25, .75)
+	// Negative fitness is shifted so the least fit has no weight
+	checkShares(t, "roulette negative", pickShares(&RouletteParentSelector{}, individuals(-5, -1, -5), 0), 0, 1, 0)
+	// With whole numbers of expected picks, SUS gives exactly those
+	checkShares(t, "sus", pickShares(&StochasticUniversalParentSelector{}, individuals(0, 1, 3, 0), 0), 0, .25, .75, 0)
+	checkShares(t, "linear rank", pickShares(&LinearRankParentSelector{Pressure: 2}, individuals(5, 1, 3), 0), 2./3, 0, 1./3)
+	checkShares(t, "exponential rank", pickShares(&ExponentialRankParentSelector{Base: .5}, individuals(1, 2, 3), 0), 1/7., 2/7., 4/7.)
+	checkShares(t, "truncation", pickShares(&TruncationParentSelector{Fraction: .5}, individuals(4, 1, 3, 2), 0), .5, 0, .5, 0)
+
+	// All zero fitness is a uniform pick, not a division by zero
+	for _, ps := range []ParentSelector{&RouletteParentSelector{}, &StochasticUniversalParentSelector{}} {
+		checkShares(t, ps.String()+" all zero", pickShares(ps, individuals(0, 0, 0, 0), 0), .25, .25, .25, .25)
+	}
+}
+
+func TestBoltzmannParentSelector(t *testing.T) {
+	s := &BoltzmannParentSelector{Schedule: &LinearCooling{Initial: 1, Step: .25}}
+	c := individuals(0, 1)
+	p := 1 / (1 + math.Exp(-1))
+	checkShares(t, "boltzmann T=1", pickShares(s, c, 0), 1-p, p)
+	// Preparing doesn't change the selector, so the same generation always has the same temperature
+	checkShares(t, "boltzmann T=1 again", pickShares(s, c, 0), 1-p, p)
+	// T=0 is greedy, splitting ties
+	checkShares(t, "boltzmann T=0", pickShares(s, individuals(0, 2, 1, 2), 10), 0, .5, 0, .5)
+}
+
+func TestSUSSpreadsPicks(t *testing.T) {
+	c := individuals(1, 1, 2)
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 100; i++ {
+		indexes := (&StochasticUniversalParentSelector{}).Prepare(c, 0, r).(*preparedParentSelector).table.indexes
+		best := 0
+		for _, idx := range indexes {
+			if idx == 2 {
+				best++
+			}
+		}
+		// Expected 1.5 of 3 slots, so SUS always gives it 1 or 2
+		if best < 1 || best > 2 {
+			t.Fatalf("sus gave the best %d of %v", best, indexes)
+		}
+	}
+}
+
+func TestParentSelectorsSingleIndividual(t *testing.T) {
+	c := individuals(7)
+	r := rand.New(rand.NewSource(1))
+	for _, ps := range []ParentSelector{
+		TournamentParentSelector{K: 3},
+		&RouletteParentSelector{},
+		&StochasticUniversalParentSelector{},
+		&LinearRankParentSelector{},
+		&ExponentialRankParentSelector{},
+		&BoltzmannParentSelector{Schedule: &ExponentialCooling{Initial: 10, Alpha: .9}},
+		&TruncationParentSelector{Fraction: .1},
+	} {
+		if got := PrepareParentSelector(ps, c, 3, r).PickParent(c, r); got != 0 {
+			t.Errorf("%s picked %d from a population of 1", ps, got)
+		}
+		// Unprepared picks work too
+		if got := ps.PickParent(c, r); got != 0 {
+			t.Errorf("unprepared %s picked %d from a population of 1", ps, got)
+		}
+	}
+}
+
+func TestPreparedForDifferentPopulation(t *testing.T) {
+	c := individuals(1, 2, 3)
+	r := rand.New(rand.NewSource(1))
+	prepared := PrepareParentSelector(&RouletteParentSelector{}, c, 0, r)
+	if _, ok := PrepareParentSelector(TournamentParentSelector{K: 2}, c, 0, r).(TournamentParentSelector); !ok {
+		t.Error("preparing a selector that doesn't need it should return it unchanged")
+	}
+	defer func() {
+		if recover() == nil {
+			t.Error("expected picking from another population to panic")
+		}
+	}()
+	prepared.PickParent(individuals(1, 2, 3), r)
+}
@@ -0,0 +1,85 @@
+package genetic
+
+import (
+	"math/bits"
+	"math/rand"
+)
+
+// Philox4x32-10, a counter based generator from "Parallel Random Numbers: As Easy as 1, 2, 3" (Salmon et al).  Each
+// output block is a pure function of a key and a counter, so a stream can start anywhere without generating what
+// comes before it.
+const (
+	philoxM0 = 0xD2511F53
+	philoxM1 = 0xCD9E8D57
+	philoxW0 = 0x9E3779B9
+	philoxW1 = 0xBB67AE85
+)
+
+func philox4x32(counter [4]uint32, key [2]uint32) [4]uint32 {
+	for round := 0; round < 10; round++ {
+		if round > 0 {
+			key[0] += philoxW0
+			key[1] += philoxW1
+		}
+		hi0, lo0 := bits.Mul32(philoxM0, counter[0])
+		hi1, lo1 := bits.Mul32(philoxM1, counter[2])
+		counter = [4]uint32{hi1 ^ counter[1] ^ key[0], lo1, hi0 ^ counter[3] ^ key[1], lo0}
+	}
+	return counter
+}
+
+// philoxStream is the stream for one (generation, slot, purpose).  counter[0] counts blocks and the other three
+// words identify the stream.
+type philoxStream struct {
+	key     [2]uint32
+	counter [4]uint32
+	block   [4]uint32
+	used    int
+}
+
+func (p *philoxStream) next32() uint32 {
+	if p.used == 0 || p.used == len(p.block) {
+		p.block = philox4x32(p.counter, p.key)
+		p.counter[0]++
+		p.used = 0
+	}
+	ret := p.block[p.used]
+	p.used++
+	return ret
+}
+
+func (p *philoxStream) Uint64() uint64 {
+	return uint64(p.next32())<<32 | uint64(p.next32())
+}
+
+func (p *philoxStream) Int63() int64 {
+	return int64(p.Uint64() >> 1)
+}
+
+// Seed restarts the stream with a new key
+func (p *philoxStream) Seed(seed int64) {
+	p.key = [2]uint32{uint32(seed), uint32(uint64(seed) >> 32)}
+	p.counter[0] = 0
+	p.used = 0
+}
+
+var _ rand.Source64 = &philoxStream{}
+
+type philoxRandForIndex struct {
+	key [2]uint32
+}
+
+// PhiloxRandForIndex keys a Philox generator with seed and gives every (generation, slot, purpose) its own counter
+// range.  Creating a stream is O(1) and needs no locking.
+func PhiloxRandForIndex(seed uint64) RandForIndex {
+	return &philoxRandForIndex{
+		key: [2]uint32{uint32(seed), uint32(seed >> 32)},
+	}
+}
+
+func (p *philoxRandForIndex) Rand(generation int, slot int, purpose Purpose) Rand {
+	return rand.New(&philoxStream{
+		key:     p.key,
+		counter: [4]uint32{0, uint32(slot), uint32(generation), uint32(purpose)},
+	})
+}
@@ -3,33 +3,64 @@ package genetic
 import (
 	"sort"
 	"sync"
+	"sync/atomic"
 )
 
 type Population struct {
 	Individuals []Chromosome
 	isSorted    bool
+	// ages[i] is how many generations Individuals[i] has survived.  nil means everyone is new.
+	ages  []int
+	stats *Stats
 }
 
-func SpawnPopulation(n int, f ChromosomeFactory, r Rand) Population {
+func SpawnPopulation(n int, f ChromosomeFactory, rnd RandForIndex) Population {
 	ret := Population{
 		Individuals: make([]Chromosome, n),
 	}
 	for i := range ret.Individuals {
-		ret.Individuals[i] = f.Spawn(r)
+		ret.Individuals[i] = f.Spawn(rnd.Rand(0, i, PurposeSpawn))
 	}
 	return ret
 }
 
+func (p *Population) age(idx int) int {
+	if p.ages == nil {
+		return 0
+	}
+	return p.ages[idx]
+}
+
 func (p *Population) Sort() {
 	if p.isSorted {
 		return
 	}
+	if p.ages != nil {
+		sort.Sort(byFitnessWithAge{p})
+		p.isSorted = true
+		return
+	}
 	sort.Slice(p.Individuals, func(i, j int) bool {
 		return p.Individuals[i].Fitness() < p.Individuals[j].Fitness()
 	})
 	p.isSorted = true
 }
 
+type byFitnessWithAge struct {
+	p *Population
+}
+
+func (b byFitnessWithAge) Len() int { return len(b.p.Individuals) }
+
+func (b byFitnessWithAge) Less(i, j int) bool {
+	return b.p.Individuals[i].Fitness() < b.p.Individuals[j].Fitness()
+}
+
+func (b byFitnessWithAge) Swap(i, j int) {
+	b.p.Individuals[i], b.p.Individuals[j] = b.p.Individuals[j], b.p.Individuals[i]
+	b.p.ages[i], b.p.ages[j] = b.p.ages[j], b.p.ages[i]
+}
+
 func (p *Population) Min() Chromosome {
 	worst := p.Individuals[0]
 	for i := 1; i < len(p.Individuals); i++ {
@@ -80,12 +111,14 @@ func (p *Population) calculateFitnessForAll(numGoroutine int) {
 // END PLAY1OMIT
 
 
-func (p *Population) calculateFitness(numGoroutine int) {
+// calculateFitness makes sure every individual has a fitness, and returns how many had to be computed
+func (p *Population) calculateFitness(numGoroutine int) int64 {
+	var computed int64
 	if numGoroutine < 2 {
 		for i := 0; i < len(p.Individuals); i++ {
-			p.Individuals[i].Fitness()
+			computed += evaluate(p.Individuals[i])
 		}
-		return
+		return computed
 	}
 	var wg sync.WaitGroup
 	wg.Add(numGoroutine)
@@ -94,7 +127,7 @@ func (p *Population) calculateFitness(numGoroutine int) {
 		go func() {
 			defer wg.Done()
 			for individual := range individuals {
-				individual.Fitness()
+				atomic.AddInt64(&computed, evaluate(individual))
 			}
 		}()
 	}
@@ -103,6 +136,15 @@ func (p *Population) calculateFitness(numGoroutine int) {
 	}
 	close(individuals)
 	wg.Wait()
+	return computed
+}
+
+func evaluate(c Chromosome) int64 {
+	if asCached, ok := c.(CachedFitness); ok && asCached.FitnessCached() {
+		return 0
+	}
+	c.Fitness()
+	return 1
 }
 
 func (p *Population) singleNextGenerationIteration(ps ParentSelector, b Crossover, m Mutation, numP int, rnd Rand) Chromosome {
@@ -115,10 +157,16 @@ func (p *Population) singleNextGenerationIteration(ps ParentSelector, b Crossove
 	return mutatedChild
 }
 
-func (p *Population) NextGeneration(ps ParentSelector, b Crossover, m Mutation, numP int, numGoroutine int, rnd RandForIndex) Population {
+// NextGeneration breeds numChildren offspring from p.  The last one is always a mutation of the fittest individual
+// in p.
+func (p *Population) NextGeneration(ps ParentSelector, b Crossover, m Mutation, numP int, numChildren int, numGoroutine int, generation int, rnd RandForIndex) Population {
 	p.calculateFitness(numGoroutine)
+	if numChildren <= 0 {
+		return Population{}
+	}
+	ps = PrepareParentSelector(ps, p.Individuals, generation, rnd.Rand(generation, 0, PurposeParentSelection))
 	ret := Population{
-		Individuals: make([]Chromosome, len(p.Individuals)),
+		Individuals: make([]Chromosome, numChildren),
 	}
 	if numGoroutine < 2 {
 		numGoroutine = 1
@@ -130,16 +178,16 @@ func (p *Population) NextGeneration(ps ParentSelector, b Crossover, m Mutation,
 		go func() {
 			defer wg.Done()
 			for idx := range idxChan {
-				ret.Individuals[idx] = p.singleNextGenerationIteration(ps, b, m, numP, rnd.Rand(idx))
+				ret.Individuals[idx] = p.singleNextGenerationIteration(ps, b, m, numP, rnd.Rand(generation, idx, PurposeBreed))
 			}
 		}()
 	}
-	for i := 0; i < len(p.Individuals)-1; i++ {
+	for i := 0; i < numChildren-1; i++ {
 		idxChan <- i
 	}
 	close(idxChan)
 	wg.Wait()
-	ret.Individuals[len(ret.Individuals)-1] = m.Mutate(p.Max(), rnd.Rand(0))
+	ret.Individuals[len(ret.Individuals)-1] = m.Mutate(p.Max(), rnd.Rand(generation, 0, PurposeElite))
 	return ret
 }
 
@@ -5,27 +5,28 @@ import (
 	"sync"
 )
 
-type RandForIndex interface {
-	Rand(idx int) Rand
-}
+// Purpose says what a random stream is used for, so the same generation and slot can have independent streams for
+// different jobs
+type Purpose int
 
-func ArrayRandForIdx(size int, seed int64, generator func(seed int64) Rand) RandForIndex {
-	start := generator(seed)
-	ret := &arrayRandForIdx{
-		rands: make([]Rand, size),
-	}
-	for i := 0; i < size; i++ {
-		ret.rands[i] = generator(start.Int63())
-	}
-	return ret
-}
-
-type arrayRandForIdx struct {
-	rands []Rand
-}
+const (
+	PurposeSpawn Purpose = iota
+	PurposeBreed
+	PurposeElite
+	PurposeParentSelection
+	PurposeSurvivorSelection
+	PurposeTermination
+	PurposeMutationRate
+	// PurposeInitialMutationRate is the reset before generation 0, which would otherwise share generation 0's
+	// PurposeMutationRate stream
+	PurposeInitialMutationRate
+)
 
-func (a *arrayRandForIdx) Rand(idx int) Rand {
-	return a.rands[idx]
+// RandForIndex hands out a random stream for each (generation, slot, purpose).  The stream must depend only on those
+// three values, so runs are reproducible no matter the population size, the number of goroutines, or which
+// goroutine asks for which slot.  Any slot is valid.
+type RandForIndex interface {
+	Rand(generation int, slot int, purpose Purpose) Rand
 }
 
 type LockedRand struct {
@@ -51,10 +52,31 @@ func (l *LockedRand) Int63() int64 {
 	return l.G.Int63()
 }
 
+func (l *LockedRand) Float64() float64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.G.Float64()
+}
+
+func (l *LockedRand) Perm(n int) []int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.G.Perm(n)
+}
+
+func (l *LockedRand) Shuffle(n int, swap func(i, j int)) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.G.Shuffle(n, swap)
+}
+
 type Rand interface {
 	Intn(int) int
 	Int() int
 	Int63() int64
+	Float64() float64
+	Perm(n int) []int
+	Shuffle(n int, swap func(i, j int))
 }
 
 var _ Rand = &rand.Rand{}
@@ -0,0 +1,240 @@
+package genetic
+
+import (
+	"encoding"
+	"encoding/binary"
+	"errors"
+	"math/bits"
+	"math/rand"
+)
+
+// Source is a random source whose state can be saved with MarshalBinary and restored with UnmarshalBinary, and that
+// can be split into independent streams.  Wrap it with rand.New to get a Rand.
+type Source interface {
+	rand.Source64
+	encoding.BinaryMarshaler
+	encoding.BinaryUnmarshaler
+	// Split returns a new, independent stream identified by idx.  The receiver is not changed, so splitting the same
+	// state by the same idx always gives the same stream.
+	Split(idx uint64) Source
+}
+
+// splitMix64 is used to expand seeds into full generator state
+func splitMix64(x *uint64) uint64 {
+	*x += 0x9e3779b97f4a7c15
+	z := *x
+	z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+	z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+	return z ^ (z >> 31)
+}
+
+var errBadState = errors.New("invalid random source state")
+
+func marshalWords(magic string, words ...uint64) []byte {
+	ret := make([]byte, len(magic)+8*len(words))
+	copy(ret, magic)
+	for i, w := range words {
+		binary.BigEndian.PutUint64(ret[len(magic)+8*i:], w)
+	}
+	return ret
+}
+
+func unmarshalWords(data []byte, magic string, words ...*uint64) error {
+	if len(data) != len(magic)+8*len(words) || string(data[:len(magic)]) != magic {
+		return errBadState
+	}
+	for i, w := range words {
+		*w = binary.BigEndian.Uint64(data[len(magic)+8*i:])
+	}
+	return nil
+}
+
+// PCG is the 128 bit PCG-XSL-RR generator (pcg64).  Split uses PCG's built in support for multiple streams.
+type PCG struct {
+	stateHi, stateLo uint64
+	// incHi, incLo is the stream.  incLo is always odd.
+	incHi, incLo uint64
+}
+
+const (
+	pcgMulHi = 0x2360ed051fc65da4
+	pcgMulLo = 0x4385df649fccf645
+)
+
+func NewPCG(seed int64) *PCG {
+	ret := &PCG{}
+	ret.Seed(seed)
+	return ret
+}
+
+// seed follows pcg64's srandom: the stream is fixed first, then the state is mixed in
+func (p *PCG) seed(stateHi, stateLo, streamHi, streamLo uint64) {
+	p.incHi = streamHi<<1 | streamLo>>63
+	p.incLo = streamLo<<1 | 1
+	p.stateHi, p.stateLo = 0, 0
+	p.step()
+	var carry uint64
+	p.stateLo, carry = bits.Add64(p.stateLo, stateLo, 0)
+	p.stateHi, _ = bits.Add64(p.stateHi, stateHi, carry)
+	p.step()
+}
+
+func (p *PCG) Seed(seed int64) {
+	x := uint64(seed)
+	p.seed(splitMix64(&x), splitMix64(&x), splitMix64(&x), splitMix64(&x))
+}
+
+func (p *PCG) step() {
+	hi, lo := bits.Mul64(p.stateLo, pcgMulLo)
+	hi += p.stateHi*pcgMulLo + p.stateLo*pcgMulHi
+	var carry uint64
+	p.stateLo, carry = bits.Add64(lo, p.incLo, 0)
+	p.stateHi, _ = bits.Add64(hi, p.incHi, carry)
+}
+
+func (p *PCG) Uint64() uint64 {
+	p.step()
+	return bits.RotateLeft64(p.stateHi^p.stateLo, -int(p.stateHi>>58))
+}
+
+func (p *PCG) Int63() int64 {
+	return int64(p.Uint64() >> 1)
+}
+
+// Advance jumps the generator ahead by delta steps in O(log delta)
+func (p *PCG) Advance(delta uint64) {
+	accMulHi, accMulLo := uint64(0), uint64(1)
+	accIncHi, accIncLo := uint64(0), uint64(0)
+	curMulHi, curMulLo := uint64(pcgMulHi), uint64(pcgMulLo)
+	curIncHi, curIncLo := p.incHi, p.incLo
+	for delta > 0 {
+		if delta&1 == 1 {
+			accMulHi, accMulLo = mul128(accMulHi, accMulLo, curMulHi, curMulLo)
+			accIncHi, accIncLo = mul128(accIncHi, accIncLo, curMulHi, curMulLo)
+			accIncHi, accIncLo = add128(accIncHi, accIncLo, curIncHi, curIncLo)
+		}
+		plusOneHi, plusOneLo := add128(curMulHi, curMulLo, 0, 1)
+		curIncHi, curIncLo = mul128(plusOneHi, plusOneLo, curIncHi, curIncLo)
+		curMulHi, curMulLo = mul128(curMulHi, curMulLo, curMulHi, curMulLo)
+		delta >>= 1
+	}
+	p.stateHi, p.stateLo = mul128(accMulHi, accMulLo, p.stateHi, p.stateLo)
+	p.stateHi, p.stateLo = add128(p.stateHi, p.stateLo, accIncHi, accIncLo)
+}
+
+func mul128(aHi, aLo, bHi, bLo uint64) (uint64, uint64) {
+	hi, lo := bits.Mul64(aLo, bLo)
+	return hi + aHi*bLo + aLo*bHi, lo
+}
+
+func add128(aHi, aLo, bHi, bLo uint64) (uint64, uint64) {
+	lo, carry := bits.Add64(aLo, bLo, 0)
+	hi, _ := bits.Add64(aHi, bHi, carry)
+	return hi, lo
+}
+
+func (p *PCG) Split(idx uint64) Source {
+	x := p.stateHi ^ p.incLo
+	y := p.stateLo ^ idx
+	ret := &PCG{}
+	ret.seed(splitMix64(&x), splitMix64(&y), p.incHi^splitMix64(&x), p.incLo^splitMix64(&y))
+	return ret
+}
+
+func (p *PCG) MarshalBinary() ([]byte, error) {
+	return marshalWords("pcg1", p.stateHi, p.stateLo, p.incHi, p.incLo), nil
+}
+
+func (p *PCG) UnmarshalBinary(data []byte) error {
+	var next PCG
+	if err := unmarshalWords(data, "pcg1", &next.stateHi, &next.stateLo, &next.incHi, &next.incLo); err != nil {
+		return err
+	}
+	if next.incLo&1 == 0 {
+		return errBadState
+	}
+	*p = next
+	return nil
+}
+
+var _ Source = &PCG{}
+
+// Xoshiro is the xoshiro256** generator.  Split(idx) is the state after idx+1 jumps of 2^128 steps each, so streams
+// never overlap.
+type Xoshiro struct {
+	s [4]uint64
+}
+
+func NewXoshiro(seed int64) *Xoshiro {
+	ret := &Xoshiro{}
+	ret.Seed(seed)
+	return ret
+}
+
+func (x *Xoshiro) Seed(seed int64) {
+	sm := uint64(seed)
+	for i := range x.s {
+		x.s[i] = splitMix64(&sm)
+	}
+}
+
+func (x *Xoshiro) Uint64() uint64 {
+	s := &x.s
+	ret := bits.RotateLeft64(s[1]*5, 7) * 9
+	t := s[1] << 17
+	s[2] ^= s[0]
+	s[3] ^= s[1]
+	s[1] ^= s[2]
+	s[0] ^= s[3]
+	s[2] ^= t
+	s[3] = bits.RotateLeft64(s[3], 45)
+	return ret
+}
+
+func (x *Xoshiro) Int63() int64 {
+	return int64(x.Uint64() >> 1)
+}
+
+var xoshiroJump = [4]uint64{0x180ec6d33cfd0aba, 0xd5a61266f0c9392c, 0xa9582618e03fc9aa, 0x39abdc4529b1661c}
+
+// Jump advances the generator 2^128 steps
+func (x *Xoshiro) Jump() {
+	var next [4]uint64
+	for _, jump := range xoshiroJump {
+		for b := uint(0); b < 64; b++ {
+			if jump&(1<<b) != 0 {
+				for i := range next {
+					next[i] ^= x.s[i]
+				}
+			}
+			x.Uint64()
+		}
+	}
+	x.s = next
+}
+
+func (x *Xoshiro) Split(idx uint64) Source {
+	ret := &Xoshiro{s: x.s}
+	for i := uint64(0); i <= idx; i++ {
+		ret.Jump()
+	}
+	return ret
+}
+
+func (x *Xoshiro) MarshalBinary() ([]byte, error) {
+	return marshalWords("xos1", x.s[0], x.s[1], x.s[2], x.s[3]), nil
+}
+
+func (x *Xoshiro) UnmarshalBinary(data []byte) error {
+	var next Xoshiro
+	if err := unmarshalWords(data, "xos1", &next.s[0], &next.s[1], &next.s[2], &next.s[3]); err != nil {
+		return err
+	}
+	if next.s == [4]uint64{} {
+		return errBadState
+	}
+	*x = next
+	return nil
+}
+
+var _ Source = &Xoshiro{}
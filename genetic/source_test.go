@@ -0,0 +1,86 @@
+package genetic
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestSources(t *testing.T) {
+	sources := map[string]func() Source{
+		"pcg":     func() Source { return NewPCG(1) },
+		"xoshiro": func() Source { return NewXoshiro(1) },
+	}
+	for name, newSource := range sources {
+		t.Run(name, func(t *testing.T) {
+			src := newSource()
+			src.Uint64()
+			state, err := src.MarshalBinary()
+			if err != nil {
+				t.Fatal(err)
+			}
+			expected := []uint64{src.Uint64(), src.Uint64(), src.Uint64()}
+			restored := newSource()
+			if err := restored.UnmarshalBinary(state); err != nil {
+				t.Fatal(err)
+			}
+			for i, e := range expected {
+				if got := restored.Uint64(); got != e {
+					t.Errorf("restored value %d is %d, expected %d", i, got, e)
+				}
+			}
+			if err := restored.UnmarshalBinary(state[1:]); err == nil {
+				t.Error("expected an error restoring truncated state")
+			}
+
+			first, again, second := src.Split(0), src.Split(0), src.Split(1)
+			for i := 0; i < 10; i++ {
+				a, b, c := first.Uint64(), again.Uint64(), second.Uint64()
+				if a != b {
+					t.Error("expected the same split to give the same stream")
+				}
+				if a == c {
+					t.Error("expected different splits to give different streams")
+				}
+			}
+			r := rand.New(newSource())
+			if f := r.Float64(); f < 0 || f >= 1 {
+				t.Errorf("invalid Float64 %f", f)
+			}
+		})
+	}
+}
+
+func TestPCGAdvance(t *testing.T) {
+	stepped := NewPCG(7)
+	jumped := NewPCG(7)
+	for i := 0; i < 1000; i++ {
+		stepped.Uint64()
+	}
+	jumped.Advance(1000)
+	if stepped.Uint64() != jumped.Uint64() {
+		t.Error("expected Advance(1000) to match 1000 steps")
+	}
+}
+
+func TestPhilox(t *testing.T) {
+	// Known answers from the Random123 distribution
+	if got := philox4x32([4]uint32{}, [2]uint32{}); got != [4]uint32{0x6627e8d5, 0xe169c58d, 0xbc57ac4c, 0x9b00dbd8} {
+		t.Errorf("unexpected zero block %x", got)
+	}
+	ones := [4]uint32{0xffffffff, 0xffffffff, 0xffffffff, 0xffffffff}
+	if got := philox4x32(ones, [2]uint32{0xffffffff, 0xffffffff}); got != [4]uint32{0x408f276d, 0x41c83b0e, 0xa20bc7c6, 0x6d5451fd} {
+		t.Errorf("unexpected ones block %x", got)
+	}
+
+	rnd := PhiloxRandForIndex(42)
+	// A huge slot is as valid as a small one, and asking twice gives the same stream
+	first := rnd.Rand(3, 1<<20, PurposeBreed).Int63()
+	if again := PhiloxRandForIndex(42).Rand(3, 1<<20, PurposeBreed).Int63(); first != again {
+		t.Error("expected the same key to give the same stream")
+	}
+	for _, other := range []Rand{rnd.Rand(4, 1<<20, PurposeBreed), rnd.Rand(3, 1<<20+1, PurposeBreed), rnd.Rand(3, 1<<20, PurposeElite)} {
+		if other.Int63() == first {
+			t.Error("expected different keys to give different streams")
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package genetic
+
+import (
+	"hash/fnv"
+	"io"
+	"math"
+	"time"
+)
+
+// Stats describes a run as of one generation.  Algorithm computes it once per generation and shares the same values
+// with its log and every Termination, through Population.Stats.
+type Stats struct {
+	Generation int `json:"generation"`
+	// Evaluations is how many times fitness was actually computed so far, not counting cached values
+	Evaluations int64         `json:"evaluations"`
+	Elapsed     time.Duration `json:"elapsed"`
+	Best        int           `json:"best"`
+	Worst       int           `json:"worst"`
+	Mean        float64       `json:"mean"`
+	StdDev      float64       `json:"stddev"`
+	// Diversity is the fraction of individuals with a distinct genotype, from 1/len(population) to 1
+	Diversity float64 `json:"diversity"`
+}
+
+func computeStats(p *Population) Stats {
+	if len(p.Individuals) == 0 {
+		return Stats{}
+	}
+	ret := Stats{
+		Best:  p.Max().Fitness(),
+		Worst: p.Min().Fitness(),
+		Mean:  p.Average(),
+	}
+	variance := 0.0
+	genotypes := make(map[uint64]struct{}, len(p.Individuals))
+	for _, c := range p.Individuals {
+		diff := float64(c.Fitness()) - ret.Mean
+		variance += diff * diff
+		genotypes[genotypeHash(c)] = struct{}{}
+	}
+	ret.StdDev = math.Sqrt(variance / float64(len(p.Individuals)))
+	ret.Diversity = float64(len(genotypes)) / float64(len(p.Individuals))
+	return ret
+}
+
+func genotypeHash(c Chromosome) uint64 {
+	if asGenotype, ok := c.(Genotype); ok {
+		return asGenotype.GenotypeHash()
+	}
+	h := fnv.New64a()
+	mustWrite(io.WriteString(h, c.String()))
+	return h.Sum64()
+}
+
+func mustWrite(_ int, err error) {
+	if err != nil {
+		panic(err)
+	}
+}
+
+// Stats returns the statistics Algorithm attached to this generation, or just the population statistics if this
+// population isn't part of a run.
+func (p *Population) Stats() Stats {
+	if p.stats == nil {
+		return computeStats(p)
+	}
+	return *p.stats
+}
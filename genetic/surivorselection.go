@@ -1,6 +1,9 @@
 package genetic
 
-import "fmt"
+import (
+	"fmt"
+	"sort"
+)
 
 type SurvivorSelection interface {
 	NextGeneration(previous *Population, candidate *Population, r Rand) Population
@@ -15,32 +18,203 @@ func (p *ParentSurvivorSelection) String() string {
 	return fmt.Sprintf("parent-select-%s", p.ParentSelector.String())
 }
 
+// NextGeneration picks len(previous.Individuals) distinct survivors from previous and candidate with ParentSelector,
+// so the population keeps its size whatever the offspring size.  The selector is prepared once per round, and picks
+// of someone already picked are dropped.  Each round then drops the picked from the pool and tries again for the
+// rest, unless it found fewer than half of them, in which case the rest are the fittest left.  That bounds the
+// rounds to log2 n, even for a selector that keeps picking the same one.
 func (p *ParentSurvivorSelection) NextGeneration(previous *Population, candidate *Population, r Rand) Population {
-	allParents := make([]Chromosome, 0, len(previous.Individuals)+len(candidate.Individuals))
-	allParents = append(allParents, previous.Individuals...)
-	allParents = append(allParents, candidate.Individuals...)
+	pool := make([]Chromosome, 0, len(previous.Individuals)+len(candidate.Individuals))
+	pool = append(pool, previous.Individuals...)
+	pool = append(pool, candidate.Individuals...)
 	var ret Population
-	alreadyPickedParents := make(map[int]struct{})
-	for i := 0; i < (len(previous.Individuals)+len(candidate.Individuals))/2; i++ {
-		newIdx := p.ParentSelector.PickParent(allParents, r)
-		// We don't want the same person twice in the next generation
-		// We don't want to update allParents each iteration, since that turns this from O(N) to O(N^2)
-		// So we kinda cheat and only update allParents if we pick the same index twice
-		if _, contains := alreadyPickedParents[newIdx]; contains {
-			// Remove all alreadyPickedParents from allParents and reset alreadyPickedParents
-			newAllParents := make([]Chromosome, 0, len(allParents))
-			for i := range allParents {
-				if _, contains := alreadyPickedParents[i]; !contains {
-					newAllParents = append(newAllParents, allParents[i])
-				}
+	for need := len(previous.Individuals); need > 0; need = len(previous.Individuals) - len(ret.Individuals) {
+		// Survivor selection doesn't know the generation, so preparing uses 0 like an unprepared PickParent
+		ps := PrepareParentSelector(p.ParentSelector, pool, 0, r)
+		picked := make(map[int]struct{}, need)
+		for i := 0; i < need; i++ {
+			picked[ps.PickParent(pool, r)] = struct{}{}
+		}
+		remaining := make([]Chromosome, 0, len(pool)-len(picked))
+		for i, c := range pool {
+			if _, exists := picked[i]; exists {
+				ret.Individuals = append(ret.Individuals, c)
+			} else {
+				remaining = append(remaining, c)
 			}
-			allParents = newAllParents
-			alreadyPickedParents = make(map[int]struct{})
-			newIdx = p.ParentSelector.PickParent(allParents, r)
 		}
-		ret.Individuals = append(ret.Individuals, allParents[newIdx])
+		pool = remaining
+		if len(picked) < (need+1)/2 {
+			sort.SliceStable(pool, func(i, j int) bool {
+				return pool[i].Fitness() > pool[j].Fitness()
+			})
+			ret.Individuals = append(ret.Individuals, pool[:need-len(picked)]...)
+			break
+		}
 	}
 	return ret
 }
 
 var _ SurvivorSelection = &ParentSurvivorSelection{}
+
+type survivor struct {
+	individual Chromosome
+	age        int
+}
+
+// survivorPool returns everyone in previous, one generation older, followed by everyone in candidate.  Previous
+// individuals older than maxAge are skipped when maxAge > 0.
+func survivorPool(previous *Population, candidate *Population, maxAge int) (pool []survivor, expired []survivor) {
+	if previous != nil {
+		for i, c := range previous.Individuals {
+			s := survivor{individual: c, age: previous.age(i) + 1}
+			if maxAge > 0 && s.age > maxAge {
+				expired = append(expired, s)
+				continue
+			}
+			pool = append(pool, s)
+		}
+	}
+	if candidate != nil {
+		for _, c := range candidate.Individuals {
+			pool = append(pool, survivor{individual: c})
+		}
+	}
+	return pool, expired
+}
+
+// sortSurvivors orders s from most to least fit.  Ties go to the younger individual, so offspring can replace an
+// equally good parent.
+func sortSurvivors(s []survivor) {
+	sort.SliceStable(s, func(i, j int) bool {
+		fi, fj := s[i].individual.Fitness(), s[j].individual.Fitness()
+		if fi != fj {
+			return fi > fj
+		}
+		return s[i].age < s[j].age
+	})
+}
+
+func survivorPopulation(s []survivor) Population {
+	ret := Population{
+		Individuals: make([]Chromosome, len(s)),
+		ages:        make([]int, len(s)),
+	}
+	for i := range s {
+		ret.Individuals[i] = s[i].individual
+		ret.ages[i] = s[i].age
+	}
+	return ret
+}
+
+func survivorSize(size int, previous *Population) int {
+	if size == 0 {
+		return len(previous.Individuals)
+	}
+	return size
+}
+
+// bestSurvivors returns the size most fit of pool, topping up from the most fit of backup if pool is too small
+func bestSurvivors(pool []survivor, backup []survivor, size int) Population {
+	sortSurvivors(pool)
+	if len(pool) >= size {
+		return survivorPopulation(pool[:size])
+	}
+	sortSurvivors(backup)
+	if len(pool)+len(backup) > size {
+		backup = backup[:size-len(pool)]
+	}
+	return survivorPopulation(append(pool, backup...))
+}
+
+// PlusSurvivorSelection is (μ+λ) selection: the best Size of parents and offspring combined survive.  Size defaults
+// to the size of the previous generation.
+type PlusSurvivorSelection struct {
+	Size int
+}
+
+func (p *PlusSurvivorSelection) String() string {
+	return "plus"
+}
+
+func (p *PlusSurvivorSelection) NextGeneration(previous *Population, candidate *Population, r Rand) Population {
+	pool, _ := survivorPool(previous, candidate, 0)
+	return bestSurvivors(pool, nil, survivorSize(p.Size, previous))
+}
+
+var _ SurvivorSelection = &PlusSurvivorSelection{}
+
+// CommaSurvivorSelection is (μ,λ) selection: only the best Size offspring survive and every parent is discarded.
+// This wants more offspring than Size.  If there are fewer, the best parents fill the gap.
+type CommaSurvivorSelection struct {
+	Size int
+}
+
+func (c *CommaSurvivorSelection) String() string {
+	return "comma"
+}
+
+func (c *CommaSurvivorSelection) NextGeneration(previous *Population, candidate *Population, r Rand) Population {
+	parents, _ := survivorPool(previous, nil, 0)
+	offspring, _ := survivorPool(nil, candidate, 0)
+	return bestSurvivors(offspring, parents, survivorSize(c.Size, previous))
+}
+
+var _ SurvivorSelection = &CommaSurvivorSelection{}
+
+// GenerationalSurvivorSelection replaces the parents with the offspring, in the order they were bred, keeping only
+// the Elitism best parents.
+type GenerationalSurvivorSelection struct {
+	Size    int
+	Elitism int
+}
+
+func (g *GenerationalSurvivorSelection) String() string {
+	return fmt.Sprintf("generational-%d", g.Elitism)
+}
+
+func (g *GenerationalSurvivorSelection) NextGeneration(previous *Population, candidate *Population, r Rand) Population {
+	size := survivorSize(g.Size, previous)
+	parents, _ := survivorPool(previous, nil, 0)
+	sortSurvivors(parents)
+	elites := g.Elitism
+	if elites > len(parents) {
+		elites = len(parents)
+	}
+	if elites > size {
+		elites = size
+	}
+	next := append(make([]survivor, 0, size), parents[:elites]...)
+	parents = parents[elites:]
+	for _, c := range candidate.Individuals {
+		if len(next) == size {
+			break
+		}
+		next = append(next, survivor{individual: c})
+	}
+	for len(next) < size && len(parents) > 0 {
+		next = append(next, parents[0])
+		parents = parents[1:]
+	}
+	return survivorPopulation(next)
+}
+
+var _ SurvivorSelection = &GenerationalSurvivorSelection{}
+
+// AgeSurvivorSelection is (μ+λ) selection where a parent may only survive MaxAge generations before it is replaced,
+// no matter how fit it is.  Expired parents are only kept if there is nobody else.
+type AgeSurvivorSelection struct {
+	Size   int
+	MaxAge int
+}
+
+func (a *AgeSurvivorSelection) String() string {
+	return fmt.Sprintf("age-%d", a.MaxAge)
+}
+
+func (a *AgeSurvivorSelection) NextGeneration(previous *Population, candidate *Population, r Rand) Population {
+	pool, expired := survivorPool(previous, candidate, a.MaxAge)
+	return bestSurvivors(pool, expired, survivorSize(a.Size, previous))
+}
+
+var _ SurvivorSelection = &AgeSurvivorSelection{}
@@ -0,0 +1,107 @@
+package genetic
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func fitnesses(p Population) []int {
+	ret := make([]int, len(p.Individuals))
+	for i, c := range p.Individuals {
+		ret[i] = c.Fitness()
+	}
+	return ret
+}
+
+func checkSurvivors(t *testing.T, s SurvivorSelection, previous Population, candidate Population, want ...int) Population {
+	t.Helper()
+	next := s.NextGeneration(&previous, &candidate, nil)
+	got := fitnesses(next)
+	if len(got) != len(want) {
+		t.Fatalf("%s: got survivors %v, want %v", s, got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("%s: got survivors %v, want %v", s, got, want)
+		}
+	}
+	return next
+}
+
+func TestPlusSurvivorSelection(t *testing.T) {
+	next := checkSurvivors(t, &PlusSurvivorSelection{}, Population{Individuals: individuals(1, 5)}, Population{Individuals: individuals(3, 4, 5)}, 5, 5)
+	// A new individual wins a tie with a parent
+	if next.age(0) != 0 || next.age(1) != 1 {
+		t.Errorf("ages are %d and %d", next.age(0), next.age(1))
+	}
+	checkSurvivors(t, &PlusSurvivorSelection{Size: 1}, Population{Individuals: individuals(7)}, Population{}, 7)
+}
+
+func TestCommaSurvivorSelection(t *testing.T) {
+	checkSurvivors(t, &CommaSurvivorSelection{}, Population{Individuals: individuals(10, 9)}, Population{Individuals: individuals(1, 3, 2)}, 3, 2)
+	// λ < μ: every offspring survives and the best parents fill the gap
+	checkSurvivors(t, &CommaSurvivorSelection{}, Population{Individuals: individuals(8, 10, 9)}, Population{Individuals: individuals(1)}, 1, 10, 9)
+}
+
+func TestGenerationalSurvivorSelection(t *testing.T) {
+	checkSurvivors(t, &GenerationalSurvivorSelection{Elitism: 1}, Population{Individuals: individuals(1, 5, 3)}, Population{Individuals: individuals(2, 0, 4, 6)}, 5, 2, 0)
+	// Too few offspring: the next best parents fill the gap
+	checkSurvivors(t, &GenerationalSurvivorSelection{}, Population{Individuals: individuals(1, 5, 3)}, Population{Individuals: individuals(0)}, 0, 5, 3)
+}
+
+func TestAgeSurvivorSelection(t *testing.T) {
+	s := &AgeSurvivorSelection{MaxAge: 2}
+	previous := Population{Individuals: individuals(100, 1), ages: []int{2, 0}}
+	// 100 is now 3 generations old, so it expires even though it is the fittest
+	next := checkSurvivors(t, s, previous, Population{Individuals: individuals(5)}, 5, 1)
+	if next.age(0) != 0 || next.age(1) != 1 {
+		t.Errorf("ages are %d and %d", next.age(0), next.age(1))
+	}
+	// Expired parents are still kept if there is nobody else
+	checkSurvivors(t, s, previous, Population{}, 1, 100)
+
+	// Without new individuals, a parent ages out after MaxAge generations
+	current := Population{Individuals: individuals(50, 10)}
+	for generation := 0; generation < 3; generation++ {
+		current = s.NextGeneration(&current, &Population{Individuals: individuals(20)}, nil)
+	}
+	for _, f := range fitnesses(current) {
+		if f == 50 {
+			t.Errorf("50 survived past MaxAge: %v", fitnesses(current))
+		}
+	}
+}
+
+func TestParentSurvivorSelection(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for _, ps := range []ParentSelector{
+		TournamentParentSelector{K: 2},
+		&RouletteParentSelector{},
+		// Greedy, so it picks the same individual over and over
+		&BoltzmannParentSelector{Schedule: &LinearCooling{}},
+	} {
+		s := &ParentSurvivorSelection{ParentSelector: ps}
+		for _, sizes := range [][2]int{{10, 10}, {10, 3}, {4, 30}, {1, 0}} {
+			previous := make([]int, sizes[0])
+			candidate := make([]int, sizes[1])
+			for i := range previous {
+				previous[i] = i
+			}
+			for i := range candidate {
+				candidate[i] = sizes[0] + i
+			}
+			next := s.NextGeneration(&Population{Individuals: individuals(previous...)}, &Population{Individuals: individuals(candidate...)}, r)
+			got := fitnesses(next)
+			if len(got) != sizes[0] {
+				t.Fatalf("%s: %d survivors from %v, not %d", s, len(got), sizes, sizes[0])
+			}
+			seen := make(map[int]bool)
+			for _, f := range got {
+				if seen[f] {
+					t.Fatalf("%s: %d survived twice: %v", s, f, got)
+				}
+				seen[f] = true
+			}
+		}
+	}
+}
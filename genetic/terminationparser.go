@@ -0,0 +1,196 @@
+package genetic
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var terminationParsers = map[string]func(arg string) (Termination, error){
+	"counting": func(arg string) (Termination, error) {
+		limit, err := strconv.Atoi(arg)
+		return &CountingTermination{Limit: limit}, err
+	},
+	"consecutive": func(arg string) (Termination, error) {
+		consecutive, err := strconv.Atoi(arg)
+		return &NoImprovementTermination{Consecutive: consecutive}, err
+	},
+	"timing": func(arg string) (Termination, error) {
+		d, err := time.ParseDuration(arg)
+		return &TimingTermination{Duration: d}, err
+	},
+	"target": func(arg string) (Termination, error) {
+		target, err := strconv.Atoi(arg)
+		return &TargetFitnessTermination{Target: target}, err
+	},
+	"evaluations": func(arg string) (Termination, error) {
+		budget, err := strconv.ParseInt(arg, 10, 64)
+		return &EvaluationBudgetTermination{Budget: budget}, err
+	},
+	"stddev": func(arg string) (Termination, error) {
+		threshold, err := strconv.ParseFloat(arg, 64)
+		return &StdDevTermination{Threshold: threshold}, err
+	},
+	"meanmax": func(arg string) (Termination, error) {
+		idx := strings.LastIndex(arg, "-")
+		if idx < 0 {
+			return nil, fmt.Errorf("expected meanmax-<epsilon>-<consecutive>")
+		}
+		epsilon, err := strconv.ParseFloat(arg[:idx], 64)
+		if err != nil {
+			return nil, err
+		}
+		consecutive, err := strconv.Atoi(arg[idx+1:])
+		return &MeanConvergenceTermination{Epsilon: epsilon, Consecutive: consecutive}, err
+	},
+	"diversity": func(arg string) (Termination, error) {
+		floor, err := strconv.ParseFloat(arg, 64)
+		return &DiversityTermination{Floor: floor}, err
+	},
+}
+
+func init() {
+	// Registered here since parseMulti refers back to terminationParsers
+	RegisterTermination("multi", parseMulti)
+}
+
+func parseMulti(arg string) (Termination, error) {
+	ret := &MultiTermination{}
+	for _, part := range strings.Split(arg, ",") {
+		t, err := ParseTermination(part)
+		if err != nil {
+			return nil, err
+		}
+		ret.Executors = append(ret.Executors, t)
+	}
+	return ret, nil
+}
+
+// RegisterTermination lets ParseTermination understand name-<arg>, where parse turns <arg> into a Termination.
+// Names should match the prefix the Termination's String() uses.
+func RegisterTermination(name string, parse func(arg string) (Termination, error)) {
+	terminationParsers[name] = parse
+}
+
+// ParseTermination turns an expression like "timing-10m&(consecutive-50|target-40000)" into a Termination.  Each
+// term is written the way that Termination's String() writes it.  "!" is NOT, "&" is AND, "|" is OR, and "&" binds
+// tighter than "|".
+func ParseTermination(s string) (Termination, error) {
+	p := &terminationParser{in: s}
+	ret, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpace()
+	if p.pos != len(p.in) {
+		return nil, p.errorf("unexpected %q", p.in[p.pos:])
+	}
+	return ret, nil
+}
+
+type terminationParser struct {
+	in  string
+	pos int
+}
+
+func (p *terminationParser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("termination %q at %d: %s", p.in, p.pos, fmt.Sprintf(format, args...))
+}
+
+func (p *terminationParser) skipSpace() {
+	for p.pos < len(p.in) && p.in[p.pos] == ' ' {
+		p.pos++
+	}
+}
+
+func (p *terminationParser) consume(b byte) bool {
+	p.skipSpace()
+	if p.pos < len(p.in) && p.in[p.pos] == b {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *terminationParser) parseOr() (Termination, error) {
+	var terms []Termination
+	for {
+		t, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		terms = append(terms, t)
+		if !p.consume('|') {
+			break
+		}
+	}
+	if len(terms) == 1 {
+		return terms[0], nil
+	}
+	return &AnyTermination{Terminations: terms}, nil
+}
+
+func (p *terminationParser) parseAnd() (Termination, error) {
+	var terms []Termination
+	for {
+		t, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		terms = append(terms, t)
+		if !p.consume('&') {
+			break
+		}
+	}
+	if len(terms) == 1 {
+		return terms[0], nil
+	}
+	return &AllTermination{Terminations: terms}, nil
+}
+
+func (p *terminationParser) parseUnary() (Termination, error) {
+	if p.consume('!') {
+		t, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &NotTermination{Termination: t}, nil
+	}
+	if p.consume('(') {
+		t, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if !p.consume(')') {
+			return nil, p.errorf("expected )")
+		}
+		return t, nil
+	}
+	return p.parseTerm()
+}
+
+func (p *terminationParser) parseTerm() (Termination, error) {
+	p.skipSpace()
+	start := p.pos
+	for p.pos < len(p.in) && !strings.ContainsRune("&|!() ", rune(p.in[p.pos])) {
+		p.pos++
+	}
+	term := p.in[start:p.pos]
+	if term == "" {
+		return nil, p.errorf("expected a termination")
+	}
+	name, arg := term, ""
+	if idx := strings.Index(term, "-"); idx >= 0 {
+		name, arg = term[:idx], term[idx+1:]
+	}
+	parse, exists := terminationParsers[name]
+	if !exists {
+		return nil, p.errorf("unknown termination %q", name)
+	}
+	t, err := parse(arg)
+	if err != nil {
+		return nil, p.errorf("invalid %s: %v", term, err)
+	}
+	return t, nil
+}
@@ -1,9 +1,14 @@
 module github.com/cep21/geneticsort
 
-go 1.12
+go 1.21
 
 require (
 	github.com/aws/aws-sdk-go v1.19.45
+	golang.org/x/tools v0.0.0-20191107235519-f7ea15e60b12
+)
+
+require (
+	github.com/jmespath/go-jmespath v0.0.0-20180206201540-c2b33e8439af // indirect
 	github.com/stretchr/testify v1.3.0 // indirect
-	golang.org/x/tools v0.0.0-20191107235519-f7ea15e60b12 // indirect
+	golang.org/x/net v0.0.0-20190620200207-3b0461eec859 // indirect
 )
@@ -0,0 +1,59 @@
+package arraysort
+
+// mcIlroyAdversary is based on the "antiquicksort" implementation by M. Douglas McIlroy.
+// See https://www.cs.dartmouth.edu/~doug/mdmspe.pdf for more info.
+//
+// The values being sorted are ids, and the adversary decides each id's real value as late as possible, in whatever
+// way makes the sort do the most work.
+type mcIlroyAdversary struct {
+	data      []int // item values by id, initialized to special gas value and changed by less
+	nsolid    int   // number of elements that have been set to non-gas values
+	candidate int   // guess at current pivot
+	gas       int   // special value for unset elements, higher than everything else
+}
+
+func (d *mcIlroyAdversary) less(i, j int) bool {
+	if d.data[i] == d.gas && d.data[j] == d.gas {
+		if i == d.candidate {
+			// freeze i
+			d.data[i] = d.nsolid
+			d.nsolid++
+		} else {
+			// freeze j
+			d.data[j] = d.nsolid
+			d.nsolid++
+		}
+	}
+
+	if d.data[i] == d.gas {
+		d.candidate = i
+	} else if d.data[j] == d.gas {
+		d.candidate = j
+	}
+
+	return d.data[i] < d.data[j]
+}
+
+// Adversary builds an input of size values that target sorts slowly, by letting McIlroy's adversary answer target's
+// comparisons.  It only works against sorts that are deterministic and compare values through the Counter.
+func Adversary(target SortTarget, size int) []int {
+	d := &mcIlroyAdversary{
+		data: make([]int, size),
+		gas:  size - 1,
+	}
+	ids := make([]int, size)
+	for i := range ids {
+		d.data[i] = d.gas
+		ids[i] = i
+	}
+	target.Sort(ids, &Counter{less: d.less})
+	// A correct sort settles every value but the last, which stays gas.  Settle anything left so the result is always
+	// a permutation.
+	for id, v := range d.data {
+		if v == d.gas && d.nsolid < d.gas {
+			d.data[id] = d.nsolid
+			d.nsolid++
+		}
+	}
+	return d.data
+}
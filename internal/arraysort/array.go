@@ -1,16 +1,20 @@
 package arraysort
 
 import (
+	"encoding/binary"
 	"fmt"
+	"hash/fnv"
 	"sort"
 	"strings"
 
 	"github.com/cep21/geneticsort/genetic"
+	"github.com/cep21/geneticsort/internal/encoding"
 )
 
 type arraySortingIndividual struct {
 	vals    []int
 	fitness *int
+	eval    Evaluator
 }
 
 func (c *arraySortingIndividual) Randomize(idx int, r genetic.Rand) {
@@ -43,6 +47,10 @@ func (c *arraySortingIndividual) Len() int {
 	return len(c.vals)
 }
 
+func (c *arraySortingIndividual) Ints() []int {
+	return c.vals
+}
+
 func mustPrint(_ int, err error) {
 	if err != nil {
 		panic(err)
@@ -51,6 +59,10 @@ func mustPrint(_ int, err error) {
 
 var _ genetic.Chromosome = &arraySortingIndividual{}
 var _ genetic.Array = &arraySortingIndividual{}
+var _ genetic.CachedFitness = &arraySortingIndividual{}
+var _ genetic.Genotype = &arraySortingIndividual{}
+var _ genetic.Measurable = &arraySortingIndividual{}
+var _ encoding.Ints = &arraySortingIndividual{}
 
 func (c *arraySortingIndividual) String() string {
 	var s strings.Builder
@@ -65,17 +77,49 @@ func (c *arraySortingIndividual) String() string {
 
 type ArraySortingFactory struct {
 	IndividualSize int
+	// Target is the name of the registered SortTarget to attack.  Defaults to sort.Slice
+	Target string
+	// Cost is the name of the registered CostModel to maximize.  Defaults to comparisons
+	Cost string
+	// Evaluator, if set, overrides Target and Cost
+	Evaluator Evaluator
+	eval      Evaluator
 }
 
 var _ genetic.ChromosomeFactory = &ArraySortingFactory{}
 
+var defaultEvaluator = &TargetEvaluator{
+	Target: mustLookupSortTarget(defaultSortTarget),
+	Model:  mustLookupCostModel(defaultCostModel),
+}
+
+func (a *ArraySortingFactory) evaluator() Evaluator {
+	if a.Evaluator != nil {
+		return a.Evaluator
+	}
+	if a.eval == nil {
+		eval, err := NewTargetEvaluator(a.Target, a.Cost)
+		if err != nil {
+			panic(err)
+		}
+		a.eval = eval
+	}
+	return a.eval
+}
+
 func (a *ArraySortingFactory) Family() string {
-	return fmt.Sprintf("intarray-sort-%d", a.IndividualSize)
+	name := a.evaluator().Name()
+	if name == defaultEvaluator.Name() {
+		// Keep the family records were stored under before targets were configurable
+		return fmt.Sprintf("intarray-sort-%d", a.IndividualSize)
+	}
+	return fmt.Sprintf("intarray-sort-%d-%s", a.IndividualSize, name)
 }
 
 func (a *ArraySortingFactory) Spawn(r genetic.Rand) genetic.Chromosome {
 	c := &arraySortingIndividual{
 		vals: make([]int, a.IndividualSize),
+		eval: a.evaluator(),
 	}
 	for i := 0; i < a.IndividualSize; i++ {
 		c.vals[i] = r.Int()
@@ -86,31 +130,52 @@ func (a *ArraySortingFactory) Spawn(r genetic.Rand) genetic.Chromosome {
 func (c *arraySortingIndividual) Shell() genetic.Chromosome {
 	return &arraySortingIndividual{
 		vals: make([]int, len(c.vals)),
+		eval: c.eval,
 	}
 }
 
 func (c *arraySortingIndividual) Clone() genetic.Chromosome {
 	ret := &arraySortingIndividual{
 		vals: make([]int, len(c.vals)),
+		eval: c.eval,
 	}
 	copy(ret.vals, c.vals)
 	return ret
 }
 
+func (c *arraySortingIndividual) GenotypeHash() uint64 {
+	h := fnv.New64a()
+	var buf [8]byte
+	for _, v := range c.vals {
+		binary.LittleEndian.PutUint64(buf[:], uint64(v))
+		mustPrint(h.Write(buf[:]))
+	}
+	return h.Sum64()
+}
+
+func (c *arraySortingIndividual) FitnessCached() bool {
+	return c.fitness != nil
+}
+
 func (c *arraySortingIndividual) Fitness() int {
 	if c.fitness != nil {
 		return *c.fitness
 	}
 
-	tmpVals := make([]int, len(c.vals))
-	copy(tmpVals, c.vals)
-	comparisons := 0
-	sort.Slice(tmpVals, func(i, j int) bool {
-		comparisons++
-		return tmpVals[i] < tmpVals[j]
-	})
-	c.fitness = &comparisons
-	return comparisons
+	fitness := c.evaluator().Cost(c.vals)
+	c.fitness = &fitness
+	return fitness
+}
+
+func (c *arraySortingIndividual) evaluator() Evaluator {
+	if c.eval == nil {
+		return defaultEvaluator
+	}
+	return c.eval
+}
+
+func (c *arraySortingIndividual) Metrics() map[string]int {
+	return c.evaluator().Metrics(c.vals)
 }
 
 func (c *arraySortingIndividual) MustBeSorted() {
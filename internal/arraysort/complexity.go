@@ -0,0 +1,189 @@
+package arraysort
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/cep21/geneticsort/internal/encoding"
+)
+
+// GeneratorFunc adapts a function to a Generator
+type GeneratorFunc func(n int) []int
+
+func (g GeneratorFunc) Generate(n int) []int {
+	return g(n)
+}
+
+// StretchPattern scales a fixed array, such as a shrunk one, to any length.  Each value becomes an ascending run, so
+// the relative order of the pattern is kept.
+func StretchPattern(vals []int) Generator {
+	vals = encoding.Ranks(vals)
+	return GeneratorFunc(func(n int) []int {
+		ret := make([]int, n)
+		for i := range ret {
+			ret[i] = vals[i*len(vals)/n]*n + i
+		}
+		return ret
+	})
+}
+
+// ParseGenerator reads a program in the form a generative chromosome prints itself, such as
+// "reverse(0.100,0.500) runs(0.000,1.000,4)"
+func ParseGenerator(s string) (Generator, error) {
+	c := &generativeIndividual{}
+	for _, field := range strings.Fields(s) {
+		op, err := parsePatternOp(field)
+		if err != nil {
+			return nil, err
+		}
+		c.ops = append(c.ops, op)
+	}
+	return c, nil
+}
+
+func parsePatternOp(s string) (patternOp, error) {
+	open := strings.IndexByte(s, '(')
+	if open == -1 || !strings.HasSuffix(s, ")") {
+		if s == "nop" {
+			return patternOp{}, nil
+		}
+		return patternOp{}, fmt.Errorf("invalid op %q", s)
+	}
+	var op patternOp
+	for op.Kind = 0; op.Kind < numPatternOps; op.Kind++ {
+		if patternOpNames[op.Kind] == s[:open] {
+			break
+		}
+	}
+	if op.Kind == numPatternOps {
+		return patternOp{}, fmt.Errorf("unknown op %q: valid ops are %v", s[:open], patternOpNames)
+	}
+	args := strings.Split(s[open+1:len(s)-1], ",")
+	want := 2
+	if op.Kind == opRotate || op.Kind == opRuns || op.Kind == opQuantize {
+		want = 3
+	}
+	if len(args) != want {
+		return patternOp{}, fmt.Errorf("op %q needs %d arguments", s, want)
+	}
+	params := make([]uint16, len(args))
+	for i, arg := range args {
+		f, err := strconv.ParseFloat(arg, 64)
+		if err != nil {
+			return patternOp{}, err
+		}
+		if i == 2 && op.Kind != opRotate {
+			if f < 2 || f > 16 {
+				return patternOp{}, fmt.Errorf("op %q must split into 2 to 16 ways", s)
+			}
+			params[i] = uint16(f - 2)
+			continue
+		}
+		if f < 0 || f > 1 {
+			return patternOp{}, fmt.Errorf("op %q has an argument out of range", s)
+		}
+		params[i] = uint16(math.Round(f * (1<<16 - 1)))
+	}
+	op.Start, op.End = params[0], params[1]
+	if len(params) == 3 {
+		op.Param = params[2]
+	}
+	return op, nil
+}
+
+type ComplexityPoint struct {
+	N    int
+	Cost int
+}
+
+// ComplexityFit is cost ≈ Constant·n^Exponent, fit by least squares on log cost against log n
+type ComplexityFit struct {
+	Points   []ComplexityPoint
+	Exponent float64
+	Constant float64
+	// R2 is the coefficient of determination of the fit in log-log space
+	R2 float64
+}
+
+// GeometricSizes is min, min·factor, min·factor², ... up to max
+func GeometricSizes(min int, max int, factor float64) []int {
+	var ret []int
+	for n := float64(min); int(n) <= max; n *= factor {
+		if len(ret) == 0 || int(n) != ret[len(ret)-1] {
+			ret = append(ret, int(n))
+		}
+	}
+	return ret
+}
+
+// MeasureComplexity fits how eval's cost for g grows over sizes
+func MeasureComplexity(g Generator, eval Evaluator, sizes []int) ComplexityFit {
+	points := make([]ComplexityPoint, 0, len(sizes))
+	for _, n := range sizes {
+		points = append(points, ComplexityPoint{
+			N:    n,
+			Cost: eval.Cost(g.Generate(n)),
+		})
+	}
+	return FitComplexity(points)
+}
+
+// FitComplexity fits points with positive N and Cost.  Others are ignored, as they have no logarithm.
+func FitComplexity(points []ComplexityPoint) ComplexityFit {
+	ret := ComplexityFit{
+		Points: points,
+	}
+	var xs, ys []float64
+	for _, p := range points {
+		if p.N > 0 && p.Cost > 0 {
+			xs = append(xs, math.Log(float64(p.N)))
+			ys = append(ys, math.Log(float64(p.Cost)))
+		}
+	}
+	if len(xs) < 2 {
+		return ret
+	}
+	var meanX, meanY float64
+	for i := range xs {
+		meanX += xs[i]
+		meanY += ys[i]
+	}
+	meanX /= float64(len(xs))
+	meanY /= float64(len(ys))
+	var covXY, varX, varY float64
+	for i := range xs {
+		covXY += (xs[i] - meanX) * (ys[i] - meanY)
+		varX += (xs[i] - meanX) * (xs[i] - meanX)
+		varY += (ys[i] - meanY) * (ys[i] - meanY)
+	}
+	if varX == 0 {
+		return ret
+	}
+	ret.Exponent = covXY / varX
+	ret.Constant = math.Exp(meanY - ret.Exponent*meanX)
+	ret.R2 = 1
+	if varY != 0 {
+		ret.R2 = covXY * covXY / (varX * varY)
+	}
+	return ret
+}
+
+// Predict is the fitted cost at n
+func (f ComplexityFit) Predict(n int) float64 {
+	return f.Constant * math.Pow(float64(n), f.Exponent)
+}
+
+// String is a table of each point's cost, the fitted cost, and both compared to n·log2 n
+func (f ComplexityFit) String() string {
+	var s strings.Builder
+	mustPrint(fmt.Fprintf(&s, "cost ≈ %.3f·n^%.3f (R²=%.4f)\n", f.Constant, f.Exponent, f.R2))
+	mustPrint(fmt.Fprintf(&s, "%10s %14s %14s %10s %10s\n", "n", "cost", "fitted", "cost/nlgn", "fit/nlgn"))
+	for _, p := range f.Points {
+		nlgn := float64(p.N) * math.Log2(float64(p.N))
+		mustPrint(fmt.Fprintf(&s, "%10d %14d %14.0f %10.3f %10.3f\n", p.N, p.Cost, f.Predict(p.N),
+			Intensity(p.Cost, p.N), f.Predict(p.N)/nlgn))
+	}
+	return s.String()
+}
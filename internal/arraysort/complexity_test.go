@@ -0,0 +1,61 @@
+package arraysort
+
+import (
+	"math"
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+func TestFitComplexity(t *testing.T) {
+	var points []ComplexityPoint
+	for _, n := range GeometricSizes(16, 4096, 2) {
+		points = append(points, ComplexityPoint{N: n, Cost: 3 * n * n})
+	}
+	fit := FitComplexity(points)
+	if math.Abs(fit.Exponent-2) > 1e-9 || math.Abs(fit.Constant-3) > 1e-6 || math.Abs(fit.R2-1) > 1e-9 {
+		t.Errorf("expected 3·n^2 exactly, got %s", fit)
+	}
+}
+
+func TestMeasureComplexity(t *testing.T) {
+	insertion := &TargetEvaluator{
+		Target: insertionSortTarget,
+		Model:  mustLookupCostModel(""),
+	}
+	reversed := GeneratorFunc(func(n int) []int {
+		ret := make([]int, n)
+		for i := range ret {
+			ret[i] = n - i
+		}
+		return ret
+	})
+	if fit := MeasureComplexity(reversed, insertion, GeometricSizes(64, 2048, 2)); math.Abs(fit.Exponent-2) > .01 {
+		t.Errorf("insertion sort on reversed input should be quadratic:\n%s", fit)
+	}
+	r := rand.New(rand.NewSource(1))
+	random := GeneratorFunc(func(n int) []int {
+		return r.Perm(n)
+	})
+	if fit := MeasureComplexity(random, defaultEvaluator, GeometricSizes(1024, 65536, 2)); fit.Exponent > 1.2 {
+		t.Errorf("sort.Slice on random input should be n·log n:\n%s", fit)
+	}
+}
+
+func TestParseGenerator(t *testing.T) {
+	f := &GenerativeFactory{}
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 20; i++ {
+		c := f.Spawn(r).(*generativeIndividual)
+		parsed, err := ParseGenerator(c.String())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(parsed.Generate(1000), c.Generate(1000)) {
+			t.Errorf("%s did not parse back to the same program", c)
+		}
+	}
+	if _, err := ParseGenerator("spin(0.1,0.2)"); err == nil {
+		t.Error("expected an error for an unknown op")
+	}
+}
@@ -0,0 +1,243 @@
+package arraysort
+
+import (
+	"fmt"
+	"runtime"
+	"sort"
+	"sync"
+	"time"
+)
+
+// CostModel measures how expensive it is for target to sort vals.  Bigger is worse for the sort.  Cost must not
+// modify vals.
+type CostModel interface {
+	Name() string
+	Cost(target SortTarget, vals []int) int
+}
+
+func countedSort(target SortTarget, vals []int) Counter {
+	tmpVals := make([]int, len(vals))
+	copy(tmpVals, vals)
+	var counter Counter
+	target.Sort(tmpVals, &counter)
+	return counter
+}
+
+type counterCost struct {
+	name string
+	cost func(c Counter) int
+	// swaps is whether cost needs Counter.Swaps
+	swaps bool
+}
+
+func (c *counterCost) Name() string {
+	return c.name
+}
+
+func (c *counterCost) Cost(target SortTarget, vals []int) int {
+	return c.cost(countedSort(target, vals))
+}
+
+// AllocationCost is the average number of heap allocations per sort, measured like testing.AllocsPerRun.  Allocation
+// counts are process wide, so measurements are serialized, but other goroutines still add noise.  Use a single
+// goroutine for exact counts.
+type AllocationCost struct {
+	Runs int
+}
+
+var allocationMu sync.Mutex
+
+func (a *AllocationCost) Name() string {
+	return "allocs"
+}
+
+func (a *AllocationCost) Cost(target SortTarget, vals []int) int {
+	runs := a.Runs
+	if runs <= 0 {
+		runs = 5
+	}
+	// Copies and counters are made up front so they aren't counted
+	copies := make([][]int, runs+1)
+	counters := make([]Counter, runs+1)
+	for i := range copies {
+		copies[i] = make([]int, len(vals))
+		copy(copies[i], vals)
+	}
+	allocationMu.Lock()
+	defer allocationMu.Unlock()
+	// Warm up, like testing.AllocsPerRun
+	target.Sort(copies[runs], &counters[runs])
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	for i := 0; i < runs; i++ {
+		target.Sort(copies[i], &counters[i])
+	}
+	runtime.ReadMemStats(&after)
+	return int((after.Mallocs - before.Mallocs) / uint64(runs))
+}
+
+// WallTimeCost is the median nanoseconds to sort, over Runs runs, ignoring outliers outside 1.5 times the
+// interquartile range.
+type WallTimeCost struct {
+	Runs int
+}
+
+func (w *WallTimeCost) Name() string {
+	return "walltime"
+}
+
+func (w *WallTimeCost) Cost(target SortTarget, vals []int) int {
+	runs := w.Runs
+	if runs <= 0 {
+		runs = 5
+	}
+	tmpVals := make([]int, len(vals))
+	samples := make([]time.Duration, runs)
+	for i := range samples {
+		copy(tmpVals, vals)
+		var counter Counter
+		start := time.Now()
+		target.Sort(tmpVals, &counter)
+		samples[i] = time.Since(start)
+	}
+	return int(robustMedian(samples))
+}
+
+func robustMedian(samples []time.Duration) time.Duration {
+	sort.Slice(samples, func(i, j int) bool {
+		return samples[i] < samples[j]
+	})
+	q1, q3 := samples[len(samples)/4], samples[(3*len(samples))/4]
+	fence := (q3 - q1) * 3 / 2
+	kept := samples[:0]
+	for _, s := range samples {
+		if s >= q1-fence && s <= q3+fence {
+			kept = append(kept, s)
+		}
+	}
+	return kept[len(kept)/2]
+}
+
+const defaultCostModel = "comparisons"
+
+var costModels = builtinCostModels()
+
+func RegisterCostModel(c CostModel) {
+	costModels[c.Name()] = c
+}
+
+func LookupCostModel(name string) (CostModel, error) {
+	if name == "" {
+		name = defaultCostModel
+	}
+	c, exists := costModels[name]
+	if !exists {
+		return nil, fmt.Errorf("unknown cost model %q: valid models are %v", name, CostModelNames())
+	}
+	return c, nil
+}
+
+func mustLookupCostModel(name string) CostModel {
+	c, err := LookupCostModel(name)
+	if err != nil {
+		panic(err)
+	}
+	return c
+}
+
+// CheckCostModel returns an error if model can't measure target, such as scoring swaps of a sort that doesn't count
+// them, which would make every array equally fit
+func CheckCostModel(target SortTarget, model CostModel) error {
+	if asCounter, ok := model.(*counterCost); ok && asCounter.swaps && !countsSwaps(target) {
+		var swapping []string
+		for _, name := range SortTargetNames() {
+			if countsSwaps(sortTargets[name]) {
+				swapping = append(swapping, name)
+			}
+		}
+		return fmt.Errorf("sort target %q doesn't count swaps, so can't be scored by %s: targets that do are %v", target.Name(), model.Name(), swapping)
+	}
+	return nil
+}
+
+func CostModelNames() []string {
+	ret := make([]string, 0, len(costModels))
+	for name := range costModels {
+		ret = append(ret, name)
+	}
+	sort.Strings(ret)
+	return ret
+}
+
+func builtinCostModels() map[string]CostModel {
+	ret := make(map[string]CostModel)
+	for _, c := range []CostModel{
+		&counterCost{name: "comparisons", cost: func(c Counter) int { return c.Comparisons }},
+		&counterCost{name: "swaps", cost: func(c Counter) int { return c.Swaps }, swaps: true},
+		&counterCost{name: "comparisons+swaps", cost: func(c Counter) int { return c.Comparisons + c.Swaps }, swaps: true},
+		&AllocationCost{},
+		&WallTimeCost{},
+	} {
+		ret[c.Name()] = c
+	}
+	return ret
+}
+
+// Evaluator scores an array for the genetic algorithm.  Bigger is worse for the sort under attack.
+type Evaluator interface {
+	// Name is part of the chromosome family, so results from different evaluators are kept apart
+	Name() string
+	Cost(vals []int) int
+	// Metrics measures every cost it knows of, for recording alongside the best candidate
+	Metrics(vals []int) map[string]int
+}
+
+// TargetEvaluator scores arrays by sorting them with Target and measuring Model
+type TargetEvaluator struct {
+	Target SortTarget
+	Model  CostModel
+}
+
+func NewTargetEvaluator(target string, cost string) (*TargetEvaluator, error) {
+	t, err := LookupSortTarget(target)
+	if err != nil {
+		return nil, err
+	}
+	c, err := LookupCostModel(cost)
+	if err != nil {
+		return nil, err
+	}
+	if err := CheckCostModel(t, c); err != nil {
+		return nil, err
+	}
+	return &TargetEvaluator{
+		Target: t,
+		Model:  c,
+	}, nil
+}
+
+func (t *TargetEvaluator) Name() string {
+	if t.Model.Name() == defaultCostModel {
+		return t.Target.Name()
+	}
+	return t.Target.Name() + "-" + t.Model.Name()
+}
+
+func (t *TargetEvaluator) Cost(vals []int) int {
+	return t.Model.Cost(t.Target, vals)
+}
+
+// Metrics measures vals with every cost model that can measure Target, so swaps are left out for targets that don't
+// count them rather than recorded as 0
+func (t *TargetEvaluator) Metrics(vals []int) map[string]int {
+	ret := make(map[string]int, len(costModels))
+	for name, c := range costModels {
+		if CheckCostModel(t.Target, c) != nil {
+			continue
+		}
+		ret[name] = c.Cost(t.Target, vals)
+	}
+	return ret
+}
+
+var _ Evaluator = &TargetEvaluator{}
@@ -0,0 +1,91 @@
+package arraysort
+
+import "math"
+
+// DifferentialEvaluator scores arrays by how many more comparisons Candidate makes than Reference, finding inputs
+// where the candidate is bad relative to the reference rather than bad for every sort.
+type DifferentialEvaluator struct {
+	Candidate SortTarget
+	Reference SortTarget
+	// Ratio scores by Candidate's comparisons per thousand of Reference's, rather than by the difference
+	Ratio bool
+	// OnMismatch, if set, is called with any input the two targets sort differently.  Ints have no identity besides
+	// their value, so that means one of them sorts incorrectly.  It is called from many goroutines at once.
+	OnMismatch func(vals []int, candidate []int, reference []int)
+}
+
+func NewDifferentialEvaluator(candidate string, reference string, ratio bool) (*DifferentialEvaluator, error) {
+	c, err := LookupSortTarget(candidate)
+	if err != nil {
+		return nil, err
+	}
+	r, err := LookupSortTarget(reference)
+	if err != nil {
+		return nil, err
+	}
+	return &DifferentialEvaluator{
+		Candidate: c,
+		Reference: r,
+		Ratio:     ratio,
+	}, nil
+}
+
+func (d *DifferentialEvaluator) Name() string {
+	if d.Ratio {
+		return d.Candidate.Name() + "-over-" + d.Reference.Name()
+	}
+	return d.Candidate.Name() + "-minus-" + d.Reference.Name()
+}
+
+func (d *DifferentialEvaluator) sortBoth(vals []int) (candidate Counter, reference Counter, mismatch bool) {
+	candidateVals := make([]int, len(vals))
+	copy(candidateVals, vals)
+	d.Candidate.Sort(candidateVals, &candidate)
+	referenceVals := make([]int, len(vals))
+	copy(referenceVals, vals)
+	d.Reference.Sort(referenceVals, &reference)
+	for i := range candidateVals {
+		if candidateVals[i] != referenceVals[i] {
+			mismatch = true
+			break
+		}
+	}
+	if mismatch && d.OnMismatch != nil {
+		d.OnMismatch(vals, candidateVals, referenceVals)
+	}
+	return candidate, reference, mismatch
+}
+
+func (d *DifferentialEvaluator) score(candidate Counter, reference Counter) int {
+	if !d.Ratio {
+		return candidate.Comparisons - reference.Comparisons
+	}
+	if reference.Comparisons == 0 {
+		if candidate.Comparisons == 0 {
+			return 1000
+		}
+		return math.MaxInt32
+	}
+	return candidate.Comparisons * 1000 / reference.Comparisons
+}
+
+func (d *DifferentialEvaluator) Cost(vals []int) int {
+	candidate, reference, _ := d.sortBoth(vals)
+	return d.score(candidate, reference)
+}
+
+func (d *DifferentialEvaluator) Metrics(vals []int) map[string]int {
+	candidate, reference, mismatch := d.sortBoth(vals)
+	ret := map[string]int{
+		"candidate": candidate.Comparisons,
+		"reference": reference.Comparisons,
+		"score":     d.score(candidate, reference),
+		"mismatch":  0,
+	}
+	if mismatch {
+		ret["mismatch"] = 1
+	}
+	return ret
+}
+
+var _ Evaluator = &DifferentialEvaluator{}
@@ -0,0 +1,44 @@
+package arraysort
+
+import (
+	"sync/atomic"
+	"testing"
+)
+
+func TestDifferentialEvaluatorMismatch(t *testing.T) {
+	var mismatches int32
+	d := &DifferentialEvaluator{
+		// Forgets the last element
+		Candidate: NewSortTarget("broken", func(vals []int, c *Counter) {
+			insertionSortTarget.Sort(vals[:len(vals)-1], c)
+		}),
+		Reference: insertionSortTarget,
+		OnMismatch: func(vals []int, candidate []int, reference []int) {
+			atomic.AddInt32(&mismatches, 1)
+		},
+	}
+	if m := d.Metrics([]int{1, 2, 3})["mismatch"]; m != 0 {
+		t.Errorf("sorted input should match, got %d", m)
+	}
+	if m := d.Metrics([]int{2, 3, 1})["mismatch"]; m != 1 {
+		t.Errorf("unsorted input should mismatch, got %d", m)
+	}
+	if mismatches != 1 {
+		t.Errorf("expected one OnMismatch call, got %d", mismatches)
+	}
+	if c := d.Cost([]int{3, 2, 1}); c != -2 {
+		t.Errorf("expected 1-3 = -2 comparisons, got %d", c)
+	}
+	d.Ratio = true
+	if c := d.Cost([]int{3, 2, 1}); c != 333 {
+		t.Errorf("expected 1000*1/3 = 333, got %d", c)
+	}
+}
+
+var insertionSortTarget = NewSortTarget("insertion", func(vals []int, c *Counter) {
+	for i := 1; i < len(vals); i++ {
+		for j := i; j > 0 && c.Less(vals[j], vals[j-1]); j-- {
+			c.Swap(vals, j, j-1)
+		}
+	}
+})
@@ -0,0 +1,263 @@
+package arraysort
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"go/format"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/cep21/geneticsort/internal/encoding"
+)
+
+type RegressionCase struct {
+	Name  string
+	Input []int
+	// MaxComparisons is the most comparisons the regression test allows
+	MaxComparisons int
+}
+
+// RegressionExport turns evolved inputs into a regression test that fails if a sort starts making more comparisons
+// on any of them, and into a corpus for the test's fuzz target
+type RegressionExport struct {
+	// Package is the package clause of the test.  Defaults to sortregression
+	Package string
+	// Sort is a Go expression of type func(vals []int, cmp func(a, b int) int) for the sort under test, and Imports
+	// are the packages it needs.  Defaults to Target's sort, for the targets in regressionSorts.
+	Sort    string
+	Imports []string
+	// Target must make the same comparisons as Sort.  It measures each input's bound.  Defaults to sort.Slice, the
+	// target inputs are evolved against by default
+	Target SortTarget
+	// Slack is how many more comparisons than measured the test allows, as a fraction.  Defaults to 0.1
+	Slack float64
+	Cases []RegressionCase
+}
+
+// fuzzTarget is the name of the fuzz test, which is also the directory the corpus goes in
+const fuzzTarget = "FuzzSortComparisons"
+
+// regressionSort is how the generated test calls a standard library sort target
+type regressionSort struct {
+	expr    string
+	imports []string
+	// decls are any declarations expr needs
+	decls string
+}
+
+const cmpSliceDecl = `// cmpSlice is a sort.Interface that compares with cmp
+type cmpSlice struct {
+	vals []int
+	cmp  func(a, b int) int
+}
+
+func (c cmpSlice) Len() int           { return len(c.vals) }
+func (c cmpSlice) Less(i, j int) bool { return c.cmp(c.vals[i], c.vals[j]) < 0 }
+func (c cmpSlice) Swap(i, j int)      { c.vals[i], c.vals[j] = c.vals[j], c.vals[i] }
+`
+
+// regressionSorts are the targets a regression test can be generated for without setting Sort.  Frozen copies of
+// old releases aren't here, since the test would run whatever sort its toolchain has.
+var regressionSorts = map[string]regressionSort{
+	"slices.SortFunc": {
+		expr:    "slices.SortFunc[[]int, int]",
+		imports: []string{"slices"},
+	},
+	"sort.Slice": {
+		expr:    "func(vals []int, cmp func(a, b int) int) {\n\tsort.Slice(vals, func(i, j int) bool { return cmp(vals[i], vals[j]) < 0 })\n}",
+		imports: []string{"sort"},
+	},
+	"sort.Sort": {
+		expr:    "func(vals []int, cmp func(a, b int) int) { sort.Sort(cmpSlice{vals: vals, cmp: cmp}) }",
+		imports: []string{"sort"},
+		decls:   cmpSliceDecl,
+	},
+	"sort.Stable": {
+		expr:    "func(vals []int, cmp func(a, b int) int) { sort.Stable(cmpSlice{vals: vals, cmp: cmp}) }",
+		imports: []string{"sort"},
+		decls:   cmpSliceDecl,
+	},
+}
+
+func (e *RegressionExport) target() SortTarget {
+	if e.Target == nil {
+		return mustLookupSortTarget(defaultSortTarget)
+	}
+	return e.Target
+}
+
+// Add records vals as a test case, bounding its comparisons by what Target makes now plus Slack
+func (e *RegressionExport) Add(vals []int) {
+	target := e.target()
+	slack := e.Slack
+	if slack <= 0 {
+		slack = .1
+	}
+	vals = encoding.Ranks(vals)
+	c := countedSort(target, vals)
+	sum := sha256.Sum256(encodeFuzzInput(vals))
+	e.Cases = append(e.Cases, RegressionCase{
+		Name:           fmt.Sprintf("n%d-%s", len(vals), hex.EncodeToString(sum[:4])),
+		Input:          vals,
+		MaxComparisons: int(math.Ceil(float64(c.Comparisons) * (1 + slack))),
+	})
+}
+
+// encodeFuzzInput is how the generated fuzz target reads an array: a uvarint per value
+func encodeFuzzInput(vals []int) []byte {
+	var ret []byte
+	for _, v := range vals {
+		ret = binary.AppendUvarint(ret, uint64(v))
+	}
+	return ret
+}
+
+// WriteCorpus writes each case as a go test -fuzz corpus file under dir/testdata/fuzz/FuzzSortComparisons, named by
+// its content like the go command does
+func (e *RegressionExport) WriteCorpus(dir string) error {
+	corpusDir := filepath.Join(dir, "testdata", "fuzz", fuzzTarget)
+	if err := os.MkdirAll(corpusDir, 0755); err != nil {
+		return err
+	}
+	for _, c := range e.Cases {
+		contents := fmt.Sprintf("go test fuzz v1\n[]byte(%q)\n", encodeFuzzInput(c.Input))
+		sum := sha256.Sum256([]byte(contents))
+		if err := os.WriteFile(filepath.Join(corpusDir, hex.EncodeToString(sum[:])[:16]), []byte(contents), 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+var regressionTestTemplate = template.Must(template.New("regression").Funcs(template.FuncMap{
+	"ints": func(vals []int) string {
+		s := make([]string, len(vals))
+		for i, v := range vals {
+			s[i] = strconv.Itoa(v)
+		}
+		return strings.Join(s, ", ")
+	},
+}).Parse(`// Code generated by geneticsort; DO NOT EDIT.
+
+package {{.Package}}
+
+import (
+	"encoding/binary"
+	"math/bits"
+	"testing"
+{{range .Imports}}	{{printf "%q" .}}
+{{end}})
+
+// sortUnderTest is {{.TargetName}}, the sort these inputs were evolved against
+var sortUnderTest func(vals []int, cmp func(a, b int) int) = {{.Sort}}
+{{if .Decls}}
+{{.Decls}}{{end}}
+var sortRegressions = []struct {
+	name           string
+	input          []int
+	maxComparisons int
+}{
+{{range .Cases}}	{name: {{printf "%q" .Name}}, maxComparisons: {{.MaxComparisons}}, input: []int{ {{ints .Input}} }},
+{{end}}}
+
+func countedSort(vals []int) int {
+	comparisons := 0
+	sortUnderTest(vals, func(a, b int) int {
+		comparisons++
+		return a - b
+	})
+	return comparisons
+}
+
+func checkSorted(t *testing.T, vals []int) {
+	for i := 1; i < len(vals); i++ {
+		if vals[i-1] > vals[i] {
+			t.Fatalf("not sorted at index %d", i)
+		}
+	}
+}
+
+func TestSortRegressions(t *testing.T) {
+	for _, tc := range sortRegressions {
+		t.Run(tc.name, func(t *testing.T) {
+			vals := append([]int(nil), tc.input...)
+			if comparisons := countedSort(vals); comparisons > tc.maxComparisons {
+				t.Errorf("made %d comparisons, more than the %d allowed", comparisons, tc.maxComparisons)
+			}
+			checkSorted(t, vals)
+		})
+	}
+}
+
+// {{.FuzzTarget}} reads arrays as a uvarint per value, and allows 4·n·⌈log2 n⌉ comparisons like the standard
+// library's TestAdversary
+func {{.FuzzTarget}}(f *testing.F) {
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var vals []int
+		for len(data) > 0 {
+			v, n := binary.Uvarint(data)
+			if n <= 0 {
+				break
+			}
+			vals = append(vals, int(v%(1<<31)))
+			data = data[n:]
+		}
+		maxComparisons := 4*len(vals)*bits.Len(uint(len(vals))) + len(vals)
+		if comparisons := countedSort(vals); comparisons > maxComparisons {
+			t.Errorf("made %d comparisons sorting %d values, more than the %d allowed", comparisons, len(vals), maxComparisons)
+		}
+		checkSorted(t, vals)
+	})
+}
+`))
+
+// WriteTest writes a self contained _test.go file with every case, and a fuzz target for the corpus
+func (e *RegressionExport) WriteTest(path string) error {
+	pkg, sortExpr, imports, decls := e.Package, e.Sort, e.Imports, ""
+	if pkg == "" {
+		pkg = "sortregression"
+	}
+	target := e.target()
+	if sortExpr == "" {
+		s, exists := regressionSorts[target.Name()]
+		if !exists {
+			return fmt.Errorf("no regression test sort for target %q: set Sort, or use one of %v", target.Name(), regressionSortNames())
+		}
+		sortExpr, imports, decls = s.expr, s.imports, s.decls
+	}
+	var buf bytes.Buffer
+	err := regressionTestTemplate.Execute(&buf, map[string]interface{}{
+		"Package":    pkg,
+		"Sort":       sortExpr,
+		"TargetName": target.Name(),
+		"Decls":      decls,
+		"Imports":    imports,
+		"Cases":      e.Cases,
+		"FuzzTarget": fuzzTarget,
+	})
+	if err != nil {
+		return err
+	}
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, src, 0644)
+}
+
+func regressionSortNames() []string {
+	ret := make([]string, 0, len(regressionSorts))
+	for name := range regressionSorts {
+		ret = append(ret, name)
+	}
+	sort.Strings(ret)
+	return ret
+}
@@ -0,0 +1,91 @@
+package arraysort
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRegressionExport(t *testing.T) {
+	var e RegressionExport
+	e.Add(Adversary(mustLookupSortTarget("slices.SortFunc"), 200))
+	e.Add([]int{30, 10, 20})
+	if e.Cases[1].Input[0] != 2 || e.Cases[1].MaxComparisons < 2 {
+		t.Errorf("unexpected case %+v", e.Cases[1])
+	}
+
+	dir := t.TempDir()
+	if err := e.WriteCorpus(dir); err != nil {
+		t.Fatal(err)
+	}
+	corpus, err := os.ReadDir(filepath.Join(dir, "testdata", "fuzz", fuzzTarget))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(corpus) != 2 {
+		t.Fatalf("wrote %d corpus files, not 2", len(corpus))
+	}
+	for _, f := range corpus {
+		b, err := os.ReadFile(filepath.Join(dir, "testdata", "fuzz", fuzzTarget, f.Name()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.HasPrefix(string(b), "go test fuzz v1\n[]byte(") {
+			t.Errorf("corpus file is %q", b)
+		}
+	}
+	if err := e.WriteTest(filepath.Join(dir, "sort_regression_test.go")); err != nil {
+		t.Fatal(err)
+	}
+
+	// The generated test should pass against the sort it was measured with, corpus included
+	runGeneratedTest(t, dir)
+}
+
+func TestRegressionExportTargets(t *testing.T) {
+	for _, name := range []string{"sort.Slice", "sort.Sort", "sort.Stable"} {
+		e := RegressionExport{Target: mustLookupSortTarget(name)}
+		e.Add(Adversary(e.Target, 100))
+		dir := t.TempDir()
+		if err := e.WriteCorpus(dir); err != nil {
+			t.Fatal(err)
+		}
+		path := filepath.Join(dir, "sort_regression_test.go")
+		if err := e.WriteTest(path); err != nil {
+			t.Fatal(err)
+		}
+		src, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(string(src), "// sortUnderTest is "+name+",") {
+			t.Errorf("%s: generated test doesn't name its target", name)
+		}
+		runGeneratedTest(t, dir)
+	}
+	e := RegressionExport{Target: mustLookupSortTarget("go1.5/sort.Sort")}
+	if err := e.WriteTest(filepath.Join(t.TempDir(), "sort_regression_test.go")); err == nil {
+		t.Error("expected an error for a target the generated test can't call")
+	}
+}
+
+func runGeneratedTest(t *testing.T, dir string) {
+	t.Helper()
+	if testing.Short() {
+		t.Skip("skipping go test of the generated package in short mode")
+	}
+	goTool, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("no go command")
+	}
+	if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module sortregression\n\ngo 1.21\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cmd := exec.Command(goTool, "test", "-run", "TestSortRegressions|"+fuzzTarget, ".")
+	cmd.Dir = dir
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("generated test failed: %v\n%s", err, out)
+	}
+}
@@ -0,0 +1,250 @@
+package arraysort
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ExternalEvaluator scores arrays by asking long running worker processes, so sorts written in other languages, or
+// compiled with other flags, can be attacked.  Each worker reads one array per line on stdin, written as the length
+// followed by the values, all space separated, and answers each with one line holding the cost as an integer.
+// ServeSortWorker implements the worker side for Go sorts.
+//
+// A worker that crashes, answers garbage, or takes longer than Timeout is killed and replaced on next use, and the
+// array scores FailureCost.
+type ExternalEvaluator struct {
+	// Command is the worker's program and arguments
+	Command []string
+	// Env is added to the current environment for each worker
+	Env []string
+	// Label names the evaluator.  Defaults to the whole command, so workers run with different arguments, such as
+	// sortworker with different targets, are told apart
+	Label string
+	// Workers is how many processes to keep alive.  Defaults to 1
+	Workers int
+	// Timeout bounds each answer.  Defaults to 10 seconds
+	Timeout time.Duration
+	// FailureCost scores arrays a worker failed on.  The default of 0 ignores failures, while a large value hunts
+	// for crashes and hangs.
+	FailureCost int
+	// OnFailure, if set, is called with each array a worker failed on.  It is called from many goroutines at once.
+	OnFailure func(vals []int, err error)
+
+	once    sync.Once
+	workers chan *externalWorker
+}
+
+func (e *ExternalEvaluator) Name() string {
+	if e.Label != "" {
+		return e.Label
+	}
+	return "external-" + strings.Join(e.Command, " ")
+}
+
+func (e *ExternalEvaluator) timeout() time.Duration {
+	if e.Timeout <= 0 {
+		return 10 * time.Second
+	}
+	return e.Timeout
+}
+
+func (e *ExternalEvaluator) pool() chan *externalWorker {
+	e.once.Do(func() {
+		n := e.Workers
+		if n <= 0 {
+			n = 1
+		}
+		e.workers = make(chan *externalWorker, n)
+		for i := 0; i < n; i++ {
+			e.workers <- &externalWorker{}
+		}
+	})
+	return e.workers
+}
+
+func (e *ExternalEvaluator) Cost(vals []int) int {
+	workers := e.pool()
+	w := <-workers
+	defer func() {
+		workers <- w
+	}()
+	cost, err := w.cost(e, vals)
+	if err != nil {
+		w.kill()
+		if e.OnFailure != nil {
+			e.OnFailure(vals, err)
+		}
+		return e.FailureCost
+	}
+	return cost
+}
+
+func (e *ExternalEvaluator) Metrics(vals []int) map[string]int {
+	return map[string]int{
+		e.Name(): e.Cost(vals),
+	}
+}
+
+// Close stops every worker.  Workers are started again if the evaluator is used after Close.
+func (e *ExternalEvaluator) Close() error {
+	workers := e.pool()
+	stopped := make([]*externalWorker, 0, cap(workers))
+	for len(stopped) < cap(workers) {
+		w := <-workers
+		w.kill()
+		stopped = append(stopped, w)
+	}
+	for _, w := range stopped {
+		workers <- w
+	}
+	return nil
+}
+
+var _ Evaluator = &ExternalEvaluator{}
+
+type externalWorker struct {
+	cmd     *exec.Cmd
+	stdin   *bufio.Writer
+	closer  io.Closer
+	answers chan string
+}
+
+var errWorkerExited = errors.New("worker exited")
+
+func (w *externalWorker) start(e *ExternalEvaluator) error {
+	cmd := exec.Command(e.Command[0], e.Command[1:]...)
+	cmd.Env = append(os.Environ(), e.Env...)
+	cmd.Stderr = os.Stderr
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	answers := make(chan string)
+	go func() {
+		defer close(answers)
+		scanner := bufio.NewScanner(stdout)
+		for scanner.Scan() {
+			answers <- scanner.Text()
+		}
+	}()
+	w.cmd = cmd
+	w.stdin = bufio.NewWriter(stdin)
+	w.closer = stdin
+	w.answers = answers
+	return nil
+}
+
+func (w *externalWorker) cost(e *ExternalEvaluator, vals []int) (int, error) {
+	if w.cmd == nil {
+		if err := w.start(e); err != nil {
+			return 0, err
+		}
+	}
+	// The deadline covers writing too, since a worker that stops reading blocks the write once the pipe is full.
+	// Killing the worker unblocks both the write and the read.  A timer that can't be stopped has killed the worker.
+	process := w.cmd.Process
+	timer := time.AfterFunc(e.timeout(), func() {
+		_ = process.Kill()
+	})
+	errTimeout := fmt.Errorf("worker took longer than %s", e.timeout())
+	err := writeSortRequest(w.stdin, vals)
+	if err == nil {
+		err = w.stdin.Flush()
+	}
+	if err != nil {
+		if !timer.Stop() {
+			return 0, errTimeout
+		}
+		return 0, err
+	}
+	answer, ok := <-w.answers
+	if !timer.Stop() {
+		return 0, errTimeout
+	}
+	if !ok {
+		return 0, errWorkerExited
+	}
+	return strconv.Atoi(strings.TrimSpace(answer))
+}
+
+func (w *externalWorker) kill() {
+	if w.cmd == nil {
+		return
+	}
+	// Killing closes stdout, which ends the reading goroutine
+	_ = w.closer.Close()
+	_ = w.cmd.Process.Kill()
+	_ = w.cmd.Wait()
+	for range w.answers {
+	}
+	*w = externalWorker{}
+}
+
+func writeSortRequest(w io.Writer, vals []int) error {
+	var line strings.Builder
+	line.WriteString(strconv.Itoa(len(vals)))
+	for _, v := range vals {
+		line.WriteByte(' ')
+		line.WriteString(strconv.Itoa(v))
+	}
+	line.WriteByte('\n')
+	_, err := io.WriteString(w, line.String())
+	return err
+}
+
+func parseSortRequest(line string) ([]int, error) {
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return nil, errors.New("empty request")
+	}
+	n, err := strconv.Atoi(fields[0])
+	if err != nil {
+		return nil, err
+	}
+	if n != len(fields)-1 {
+		return nil, fmt.Errorf("request says %d values but has %d", n, len(fields)-1)
+	}
+	vals := make([]int, n)
+	for i := range vals {
+		if vals[i], err = strconv.Atoi(fields[i+1]); err != nil {
+			return nil, err
+		}
+	}
+	return vals, nil
+}
+
+// ServeSortWorker answers ExternalEvaluator requests read from r, scoring each array by sorting it with target and
+// measuring model, until r is exhausted
+func ServeSortWorker(r io.Reader, w io.Writer, target SortTarget, model CostModel) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 1<<30)
+	out := bufio.NewWriter(w)
+	for scanner.Scan() {
+		vals, err := parseSortRequest(scanner.Text())
+		if err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintln(out, model.Cost(target, vals)); err != nil {
+			return err
+		}
+		if err := out.Flush(); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
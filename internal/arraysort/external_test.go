@@ -0,0 +1,121 @@
+package arraysort
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TestMain lets the test binary stand in for an external sort worker
+func TestMain(m *testing.M) {
+	switch os.Getenv("ARRAYSORT_TEST_WORKER") {
+	case "":
+		os.Exit(m.Run())
+	case "serve":
+		if err := ServeSortWorker(os.Stdin, os.Stdout, mustLookupSortTarget(""), mustLookupCostModel("")); err != nil {
+			panic(err)
+		}
+	case "faulty":
+		// Crashes on arrays starting with 1, hangs on arrays starting with 2, and otherwise answers the length
+		scanner := bufio.NewScanner(os.Stdin)
+		for scanner.Scan() {
+			fields := strings.Fields(scanner.Text())
+			switch fields[1] {
+			case "1":
+				os.Exit(1)
+			case "2":
+				time.Sleep(time.Hour)
+			}
+			fmt.Println(fields[0])
+		}
+	case "deaf":
+		// Never reads, so large requests block the writer
+		time.Sleep(time.Hour)
+	}
+	os.Exit(0)
+}
+
+func TestExternalEvaluator(t *testing.T) {
+	e := &ExternalEvaluator{
+		Command: []string{os.Args[0]},
+		Env:     []string{"ARRAYSORT_TEST_WORKER=serve"},
+		Workers: 2,
+	}
+	defer func() {
+		if err := e.Close(); err != nil {
+			t.Error(err)
+		}
+	}()
+	for _, vals := range [][]int{{}, {3, 2, 1}, {5, 1, 4, 2, 3, 9, 8, 7, 6, 0, -1, -2, 12, 11, 10}} {
+		if got, want := e.Cost(vals), defaultEvaluator.Cost(vals); got != want {
+			t.Errorf("cost of %v: got %d want %d", vals, got, want)
+		}
+	}
+	a := &ExternalEvaluator{Command: []string{"sortworker", "-target", "sort.Sort"}}
+	b := &ExternalEvaluator{Command: []string{"sortworker", "-target", "sort.Stable"}}
+	if a.Name() == b.Name() {
+		t.Errorf("workers with different arguments are both named %q", a.Name())
+	}
+}
+
+func TestExternalEvaluatorFailures(t *testing.T) {
+	var failures int32
+	e := &ExternalEvaluator{
+		Command:     []string{os.Args[0]},
+		Env:         []string{"ARRAYSORT_TEST_WORKER=faulty"},
+		Timeout:     100 * time.Millisecond,
+		FailureCost: -1,
+		OnFailure: func(vals []int, err error) {
+			atomic.AddInt32(&failures, 1)
+		},
+	}
+	defer func() {
+		if err := e.Close(); err != nil {
+			t.Error(err)
+		}
+	}()
+	for _, tc := range []struct {
+		vals []int
+		want int
+	}{
+		{vals: []int{0, 0, 0}, want: 3},
+		{vals: []int{1, 0}, want: -1},
+		{vals: []int{0, 0}, want: 2},
+		{vals: []int{2, 0}, want: -1},
+		{vals: []int{0}, want: 1},
+	} {
+		if got := e.Cost(tc.vals); got != tc.want {
+			t.Errorf("cost of %v: got %d want %d", tc.vals, got, tc.want)
+		}
+	}
+	if failures != 2 {
+		t.Errorf("expected 2 failures, got %d", failures)
+	}
+}
+
+func TestExternalEvaluatorWriteTimeout(t *testing.T) {
+	e := &ExternalEvaluator{
+		Command:     []string{os.Args[0]},
+		Env:         []string{"ARRAYSORT_TEST_WORKER=deaf"},
+		Timeout:     100 * time.Millisecond,
+		FailureCost: -1,
+	}
+	defer func() {
+		if err := e.Close(); err != nil {
+			t.Error(err)
+		}
+	}()
+	// Far more than a pipe buffer
+	vals := make([]int, 1<<20)
+	start := time.Now()
+	if got := e.Cost(vals); got != -1 {
+		t.Errorf("got cost %d from a worker that never reads", got)
+	}
+	if elapsed := time.Since(start); elapsed > 10*time.Second {
+		t.Errorf("timing out the write took %s", elapsed)
+	}
+}
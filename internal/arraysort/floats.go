@@ -0,0 +1,221 @@
+package arraysort
+
+import (
+	"cmp"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"strconv"
+
+	"github.com/cep21/geneticsort/genetic"
+)
+
+// FloatSort sorts vals in place, counting each comparison it makes in *comparisons
+type FloatSort func(vals []float64, comparisons *int)
+
+type countingFloats struct {
+	sort.Float64Slice
+	comparisons *int
+}
+
+func (c countingFloats) Less(i, j int) bool {
+	*c.comparisons++
+	return c.Float64Slice.Less(i, j)
+}
+
+const defaultFloatSortTarget = "sort.Float64s"
+
+var floatSortTargets = map[string]FloatSort{
+	// Counting needs a comparison function, so these count the comparisons of an equivalent sort.  The oracle checks
+	// the real ones, in uncountedFloatSorts.
+	"sort.Float64s": countedFloat64s,
+	"slices.Sort": func(vals []float64, comparisons *int) {
+		slices.SortFunc(vals, func(a, b float64) int {
+			*comparisons++
+			return cmp.Compare(a, b)
+		})
+	},
+	"sort.Sort": func(vals []float64, comparisons *int) {
+		sort.Sort(countingFloats{Float64Slice: vals, comparisons: comparisons})
+	},
+	// The usual mistake: < isn't a strict weak order once there are NaNs
+	"sort.Slice/<": func(vals []float64, comparisons *int) {
+		sort.Slice(vals, func(i, j int) bool {
+			*comparisons++
+			return vals[i] < vals[j]
+		})
+	},
+}
+
+// uncountedFloatSorts are the sorts that float targets of the same name count the comparisons of
+var uncountedFloatSorts = map[string]func(vals []float64){
+	"sort.Float64s": sort.Float64s,
+	"slices.Sort":   slices.Sort[[]float64],
+}
+
+func RegisterFloatSortTarget(name string, s FloatSort) {
+	floatSortTargets[name] = s
+	delete(uncountedFloatSorts, name)
+}
+
+func LookupFloatSortTarget(name string) (FloatSort, error) {
+	if name == "" {
+		name = defaultFloatSortTarget
+	}
+	s, exists := floatSortTargets[name]
+	if !exists {
+		return nil, fmt.Errorf("unknown float sort target %q: valid targets are %v", name, FloatSortTargetNames())
+	}
+	return s, nil
+}
+
+func FloatSortTargetNames() []string {
+	ret := make([]string, 0, len(floatSortTargets))
+	for name := range floatSortTargets {
+		ret = append(ret, name)
+	}
+	sort.Strings(ret)
+	return ret
+}
+
+var specialFloats = []float64{
+	math.NaN(), math.Inf(1), math.Inf(-1), 0, math.Copysign(0, -1),
+	math.MaxFloat64, -math.MaxFloat64, math.SmallestNonzeroFloat64, -math.SmallestNonzeroFloat64,
+}
+
+// CheckFloatSort is the correctness oracle for float sorts.  sorted must be ordered like cmp.Compare, with NaNs first
+// and -0 equal to +0, and hold exactly the values of input, bit for bit.
+func CheckFloatSort(input []float64, sorted []float64) error {
+	for i := 1; i < len(sorted); i++ {
+		if cmp.Less(sorted[i], sorted[i-1]) {
+			return fmt.Errorf("%v is before %v at index %d", sorted[i-1], sorted[i], i)
+		}
+	}
+	bits := func(vals []float64) []uint64 {
+		ret := make([]uint64, len(vals))
+		for i, v := range vals {
+			ret[i] = math.Float64bits(v)
+		}
+		slices.Sort(ret)
+		return ret
+	}
+	if !slices.Equal(bits(input), bits(sorted)) {
+		return fmt.Errorf("sorting changed the values")
+	}
+	return nil
+}
+
+// FloatSortingFactory spawns float64 slices, where mutations can introduce NaN, ±Inf, signed zero and the extremes
+type FloatSortingFactory struct {
+	IndividualSize int
+	// Target is the name of a registered FloatSort.  Defaults to sort.Float64s
+	Target string
+	// SpecialRate is how often a random value is a special one.  Defaults to 0.1
+	SpecialRate float64
+	// Oracle checks every sort with CheckFloatSort, and makes any input that fails the fittest possible, which turns
+	// the algorithm into a fuzzer
+	Oracle bool
+	// OnViolation, if set, is called with each input that fails the oracle.  It is called from many goroutines at
+	// once.
+	OnViolation func(vals []float64, err error)
+
+	kind *sliceKind[float64]
+}
+
+var _ genetic.ChromosomeFactory = &FloatSortingFactory{}
+
+// violationCost is more than any sort of a reasonable array costs, but leaves room to add without overflow
+const violationCost = math.MaxInt32
+
+func (f *FloatSortingFactory) Family() string {
+	target := f.Target
+	if target == "" {
+		target = defaultFloatSortTarget
+	}
+	if f.Oracle {
+		return fmt.Sprintf("floats-sort-%d-%s-oracle", f.IndividualSize, target)
+	}
+	return fmt.Sprintf("floats-sort-%d-%s", f.IndividualSize, target)
+}
+
+func (f *FloatSortingFactory) sliceKind() *sliceKind[float64] {
+	if f.kind != nil {
+		return f.kind
+	}
+	target, err := LookupFloatSortTarget(f.Target)
+	if err != nil {
+		panic(err)
+	}
+	targetName := f.Target
+	if targetName == "" {
+		targetName = defaultFloatSortTarget
+	}
+	uncounted := uncountedFloatSorts[targetName]
+	specialRate := f.SpecialRate
+	if specialRate <= 0 {
+		specialRate = .1
+	}
+	sortChecked := func(vals []float64) (int, error) {
+		tmpVals := make([]float64, len(vals))
+		copy(tmpVals, vals)
+		comparisons := 0
+		target(tmpVals, &comparisons)
+		if !f.Oracle {
+			return comparisons, nil
+		}
+		if uncounted != nil {
+			copy(tmpVals, vals)
+			uncounted(tmpVals)
+		}
+		err := CheckFloatSort(vals, tmpVals)
+		if err != nil && f.OnViolation != nil {
+			f.OnViolation(vals, err)
+		}
+		return comparisons, err
+	}
+	f.kind = &sliceKind[float64]{
+		random: func(r genetic.Rand) float64 {
+			if r.Float64() < specialRate {
+				return specialFloats[r.Intn(len(specialFloats))]
+			}
+			// Whole numbers repeat, which sorts care about as much as special values
+			if r.Intn(2) == 0 {
+				return float64(r.Intn(1000) - 500)
+			}
+			return (r.Float64() - .5) * 1000
+		},
+		cost: func(vals []float64) int {
+			comparisons, err := sortChecked(vals)
+			if err != nil {
+				return violationCost
+			}
+			return comparisons
+		},
+		metrics: func(vals []float64) map[string]int {
+			comparisons, err := sortChecked(vals)
+			ret := map[string]int{
+				"comparisons": comparisons,
+			}
+			if f.Oracle {
+				ret["violation"] = 0
+				if err != nil {
+					ret["violation"] = 1
+				}
+			}
+			return ret
+		},
+		format: func(v float64) string {
+			return strconv.FormatFloat(v, 'g', -1, 64)
+		},
+		bytes: func(v float64) []byte {
+			return binary.LittleEndian.AppendUint64(nil, math.Float64bits(v))
+		},
+	}
+	return f.kind
+}
+
+func (f *FloatSortingFactory) Spawn(r genetic.Rand) genetic.Chromosome {
+	return newSliceIndividual(f.sliceKind(), f.IndividualSize, r)
+}
@@ -0,0 +1,10 @@
+//go:build !go1.22
+
+package arraysort
+
+import "sort"
+
+// countedFloat64s makes the comparisons sort.Float64s makes.  Before go 1.22 it is sort.Sort of a Float64Slice.
+func countedFloat64s(vals []float64, comparisons *int) {
+	sort.Sort(countingFloats{Float64Slice: vals, comparisons: comparisons})
+}
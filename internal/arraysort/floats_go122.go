@@ -0,0 +1,17 @@
+//go:build go1.22
+
+package arraysort
+
+import (
+	"cmp"
+	"slices"
+)
+
+// countedFloat64s makes the comparisons sort.Float64s makes.  Since go 1.22 it is slices.Sort, which compares like
+// slices.SortFunc with cmp.Compare.
+func countedFloat64s(vals []float64, comparisons *int) {
+	slices.SortFunc(vals, func(a, b float64) int {
+		*comparisons++
+		return cmp.Compare(a, b)
+	})
+}
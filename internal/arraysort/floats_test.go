@@ -0,0 +1,36 @@
+package arraysort
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestFloatSortTargets(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for _, name := range FloatSortTargetNames() {
+		f := &FloatSortingFactory{IndividualSize: 100, Target: name, SpecialRate: .3, Oracle: true}
+		violations := 0
+		for i := 0; i < 20; i++ {
+			violations += f.Spawn(r).(*sliceIndividual[float64]).Metrics()["violation"]
+		}
+		// Only sort.Slice with < is expected to mis-sort NaNs
+		if wantViolations := name == "sort.Slice/<"; (violations != 0) != wantViolations {
+			t.Errorf("%s: %d of 20 inputs violated the oracle", name, violations)
+		}
+	}
+}
+
+func TestCheckFloatSort(t *testing.T) {
+	nan, negZero := math.NaN(), math.Copysign(0, -1)
+	input := []float64{1, nan, negZero, 0, math.Inf(-1)}
+	if err := CheckFloatSort(input, []float64{nan, math.Inf(-1), 0, negZero, 1}); err != nil {
+		t.Errorf("signed zeros may be in either order: %v", err)
+	}
+	if err := CheckFloatSort(input, []float64{math.Inf(-1), nan, negZero, 0, 1}); err == nil {
+		t.Error("NaN must sort first")
+	}
+	if err := CheckFloatSort(input, []float64{nan, math.Inf(-1), 0, 0, 1}); err == nil {
+		t.Error("-0 must not become +0")
+	}
+}
@@ -0,0 +1,381 @@
+package arraysort
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/cep21/geneticsort/genetic"
+)
+
+// Generator is implemented by chromosomes that can build an array of any length
+type Generator interface {
+	Generate(n int) []int
+}
+
+type patternOpKind uint8
+
+const (
+	opNop patternOpKind = iota
+	opReverse
+	opRotate
+	opInterleave
+	opRuns
+	opOrganPipe
+	// opMedianOfThreeKiller is Musser's fixed sequence against median of three quicksort.  It only defeats sorts
+	// picking the median of the first, middle and last values.
+	opMedianOfThreeKiller
+	opQuantize
+	// opNintherKiller is rebuilt for each length, so it defeats the ninther and median of three pivots of sort.Sort
+	// before go 1.19 at every partition level.  See nintherKiller.
+	opNintherKiller
+	numPatternOps
+)
+
+var patternOpNames = [...]string{"nop", "reverse", "rotate", "interleave", "runs", "organpipe", "median3killer", "quantize", "nintherkiller"}
+
+// patternOp rearranges the segment [Start, End) of an array.  Start, End and Param are fractions of 1<<16 - 1, so
+// the same op means the same thing at every length.
+type patternOp struct {
+	Kind  patternOpKind
+	Start uint16
+	End   uint16
+	Param uint16
+}
+
+func randomPatternOp(r genetic.Rand) patternOp {
+	return patternOp{
+		Kind:  patternOpKind(r.Intn(int(numPatternOps))),
+		Start: uint16(r.Intn(1 << 16)),
+		End:   uint16(r.Intn(1 << 16)),
+		Param: uint16(r.Intn(1 << 16)),
+	}
+}
+
+func fraction(f uint16) float64 {
+	return float64(f) / (1<<16 - 1)
+}
+
+// ways is Param as a small count, for ops that split a segment into pieces
+func (o patternOp) ways() int {
+	return 2 + int(o.Param)%15
+}
+
+// String has enough precision for ParseGenerator to read back the same op
+func (o patternOp) String() string {
+	start, end := o.Start, o.End
+	if start > end {
+		start, end = end, start
+	}
+	switch o.Kind {
+	case opNop:
+		return "nop"
+	case opRotate:
+		return fmt.Sprintf("rotate(%.5f,%.5f,%.5f)", fraction(start), fraction(end), fraction(o.Param))
+	case opRuns, opQuantize:
+		return fmt.Sprintf("%s(%.5f,%.5f,%d)", patternOpNames[o.Kind], fraction(start), fraction(end), o.ways())
+	}
+	return fmt.Sprintf("%s(%.5f,%.5f)", patternOpNames[o.Kind], fraction(start), fraction(end))
+}
+
+func (o patternOp) apply(vals []int) {
+	start := int(fraction(o.Start) * float64(len(vals)))
+	end := int(fraction(o.End) * float64(len(vals)))
+	if start > end {
+		start, end = end, start
+	}
+	seg := vals[start:end]
+	if len(seg) < 2 {
+		return
+	}
+	switch o.Kind {
+	case opReverse:
+		for i, j := 0, len(seg)-1; i < j; i, j = i+1, j-1 {
+			seg[i], seg[j] = seg[j], seg[i]
+		}
+	case opRotate:
+		k := int(fraction(o.Param) * float64(len(seg)))
+		rotated := append(append([]int(nil), seg[k:]...), seg[:k]...)
+		copy(seg, rotated)
+	case opInterleave:
+		// A perfect shuffle of the two halves
+		half := (len(seg) + 1) / 2
+		shuffled := make([]int, 0, len(seg))
+		for i := 0; i < half; i++ {
+			shuffled = append(shuffled, seg[i])
+			if half+i < len(seg) {
+				shuffled = append(shuffled, seg[half+i])
+			}
+		}
+		copy(seg, shuffled)
+	case opRuns:
+		// Ascending runs, each taking every ways()th value
+		k := o.ways()
+		ranks := make([]int, 0, len(seg))
+		for run := 0; run < k; run++ {
+			for r := run; r < len(seg); r += k {
+				ranks = append(ranks, r)
+			}
+		}
+		arrangeByRank(seg, ranks)
+	case opOrganPipe:
+		ranks := make([]int, len(seg))
+		for i := range ranks {
+			if i%2 == 0 {
+				ranks[i/2] = i
+			} else {
+				ranks[len(seg)-1-i/2] = i
+			}
+		}
+		arrangeByRank(seg, ranks)
+	case opMedianOfThreeKiller:
+		ranks := medianOfThreeKiller(len(seg), nil, nil)
+		for i := range ranks {
+			ranks[i]--
+		}
+		arrangeByRank(seg, ranks)
+	case opNintherKiller:
+		arrangeByRank(seg, nintherKiller(len(seg)))
+	case opQuantize:
+		levels := len(seg) / o.ways()
+		if levels == 0 {
+			return
+		}
+		for i, v := range seg {
+			seg[i] = v - v%levels
+		}
+	}
+}
+
+// nintherKillers caches nintherKiller by length, since programs are generated at the same few lengths over and over
+var nintherKillers sync.Map
+
+// nintherKiller is a permutation of 0..n-1 that drives quicksort with Tukey's ninther, falling back to median of
+// three on short ranges, into its worst case.  The pivot choice depends on the range, so no fixed sequence works at
+// every level.  Instead McIlroy's adversary answers the comparisons of go1.5's sort.Sort, which settles values as
+// each partition level picks its pivot, so the structure is rebuilt recursively for n.  Go 1.19's pdqsort breaks
+// patterns and falls back to heapsort, so it stays n·log2 n on this like on any input.
+func nintherKiller(n int) []int {
+	if ranks, exists := nintherKillers.Load(n); exists {
+		return ranks.([]int)
+	}
+	ranks := Adversary(mustLookupSortTarget("go1.5/sort.Sort"), n)
+	nintherKillers.Store(n, ranks)
+	return ranks
+}
+
+// arrangeByRank puts seg's values in the order given by ranks, a permutation of 0..len(seg)-1
+func arrangeByRank(seg []int, ranks []int) {
+	sorted := append([]int(nil), seg...)
+	sort.Ints(sorted)
+	for i, r := range ranks {
+		seg[i] = sorted[r]
+	}
+}
+
+// generativeIndividual is a small program whose ops turn a sorted array of any length into an adversarial one
+type generativeIndividual struct {
+	ops     []patternOp
+	sizes   []int
+	fitness *int
+	eval    Evaluator
+}
+
+var _ genetic.Chromosome = &generativeIndividual{}
+var _ genetic.Array = &generativeIndividual{}
+var _ genetic.CachedFitness = &generativeIndividual{}
+var _ genetic.Genotype = &generativeIndividual{}
+var _ genetic.Measurable = &generativeIndividual{}
+var _ genetic.Simplifyable = &generativeIndividual{}
+var _ Generator = &generativeIndividual{}
+
+func (c *generativeIndividual) Generate(n int) []int {
+	vals := make([]int, n)
+	for i := range vals {
+		vals[i] = i
+	}
+	for _, op := range c.ops {
+		op.apply(vals)
+	}
+	return vals
+}
+
+func (c *generativeIndividual) evaluator() Evaluator {
+	if c.eval == nil {
+		return defaultEvaluator
+	}
+	return c.eval
+}
+
+// Fitness is the smallest intensity, in thousandths, over every size.  Taking the smallest rewards programs that
+// stay bad as they grow.
+func (c *generativeIndividual) Fitness() int {
+	if c.fitness != nil {
+		return *c.fitness
+	}
+	fitness := 0
+	for i, n := range c.sizes {
+		f := int(1000 * Intensity(c.evaluator().Cost(c.Generate(n)), n))
+		if i == 0 || f < fitness {
+			fitness = f
+		}
+	}
+	c.fitness = &fitness
+	return fitness
+}
+
+func (c *generativeIndividual) FitnessCached() bool {
+	return c.fitness != nil
+}
+
+func (c *generativeIndividual) Metrics() map[string]int {
+	ret := make(map[string]int, len(c.sizes))
+	for _, n := range c.sizes {
+		ret[strconv.Itoa(n)] = c.evaluator().Cost(c.Generate(n))
+	}
+	return ret
+}
+
+// Simplify turns every op that doesn't help into a nop.  Removing an op can raise fitness, so the cached fitness is
+// whatever the program scores after each removal that is kept.
+func (c *generativeIndividual) Simplify() {
+	best := c.Fitness()
+	for i, op := range c.ops {
+		if op.Kind == opNop {
+			continue
+		}
+		c.ops[i].Kind = opNop
+		c.fitness = nil
+		if f := c.Fitness(); f < best {
+			c.ops[i] = op
+		} else {
+			best = f
+		}
+	}
+	c.fitness = &best
+}
+
+func (c *generativeIndividual) String() string {
+	var s strings.Builder
+	for _, op := range c.ops {
+		if op.Kind == opNop {
+			continue
+		}
+		if s.Len() != 0 {
+			mustPrint(s.WriteString(" "))
+		}
+		mustPrint(s.WriteString(op.String()))
+	}
+	return s.String()
+}
+
+func (c *generativeIndividual) GenotypeHash() uint64 {
+	h := fnv.New64a()
+	var buf [7]byte
+	for _, op := range c.ops {
+		buf[0] = byte(op.Kind)
+		binary.LittleEndian.PutUint16(buf[1:], op.Start)
+		binary.LittleEndian.PutUint16(buf[3:], op.End)
+		binary.LittleEndian.PutUint16(buf[5:], op.Param)
+		mustPrint(h.Write(buf[:]))
+	}
+	return h.Sum64()
+}
+
+func (c *generativeIndividual) Shell() genetic.Chromosome {
+	return &generativeIndividual{
+		ops:   make([]patternOp, len(c.ops)),
+		sizes: c.sizes,
+		eval:  c.eval,
+	}
+}
+
+func (c *generativeIndividual) Clone() genetic.Chromosome {
+	ret := c.Shell().(*generativeIndividual)
+	copy(ret.ops, c.ops)
+	return ret
+}
+
+func (c *generativeIndividual) Swap(i, j int) {
+	c.ops[i], c.ops[j] = c.ops[j], c.ops[i]
+}
+
+func (c *generativeIndividual) Copy(from genetic.Array, start int, end int, into int) {
+	copy(c.ops[into:], from.(*generativeIndividual).ops[start:end])
+}
+
+func (c *generativeIndividual) Randomize(idx int, r genetic.Rand) {
+	c.ops[idx] = randomPatternOp(r)
+}
+
+func (c *generativeIndividual) Len() int {
+	return len(c.ops)
+}
+
+// GenerativeFactory spawns programs that generate arrays, rather than arrays, so the search space doesn't grow with
+// the array and the result can be checked at sizes too large to evolve directly
+type GenerativeFactory struct {
+	// Ops is the length of each program.  Defaults to 8
+	Ops int
+	// Sizes are the array lengths each program is scored at.  Defaults to 256, 1024 and 4096
+	Sizes []int
+	// Target and Cost are as in ArraySortingFactory
+	Target    string
+	Cost      string
+	Evaluator Evaluator
+	eval      Evaluator
+}
+
+var _ genetic.ChromosomeFactory = &GenerativeFactory{}
+
+func (g *GenerativeFactory) ops() int {
+	if g.Ops <= 0 {
+		return 8
+	}
+	return g.Ops
+}
+
+func (g *GenerativeFactory) sizes() []int {
+	if len(g.Sizes) == 0 {
+		return []int{256, 1024, 4096}
+	}
+	return g.Sizes
+}
+
+func (g *GenerativeFactory) evaluator() Evaluator {
+	if g.Evaluator != nil {
+		return g.Evaluator
+	}
+	if g.eval == nil {
+		eval, err := NewTargetEvaluator(g.Target, g.Cost)
+		if err != nil {
+			panic(err)
+		}
+		g.eval = eval
+	}
+	return g.eval
+}
+
+func (g *GenerativeFactory) Family() string {
+	sizes := make([]string, 0, len(g.sizes()))
+	for _, n := range g.sizes() {
+		sizes = append(sizes, strconv.Itoa(n))
+	}
+	return fmt.Sprintf("generative-%d-%s-%s", g.ops(), strings.Join(sizes, "_"), g.evaluator().Name())
+}
+
+func (g *GenerativeFactory) Spawn(r genetic.Rand) genetic.Chromosome {
+	c := &generativeIndividual{
+		ops:   make([]patternOp, g.ops()),
+		sizes: g.sizes(),
+		eval:  g.evaluator(),
+	}
+	for i := range c.ops {
+		c.ops[i] = randomPatternOp(r)
+	}
+	return c
+}
@@ -0,0 +1,80 @@
+package arraysort
+
+import (
+	"math/rand"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestGenerativeIndividualGenerate(t *testing.T) {
+	c := &generativeIndividual{
+		ops: []patternOp{
+			{Kind: opMedianOfThreeKiller, Start: 0, End: 1<<16 - 1},
+		},
+	}
+	want := medianOfThreeKiller(20, nil, nil)
+	for i := range want {
+		want[i]--
+	}
+	if got := c.Generate(20); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v want %v", got, want)
+	}
+	c.ops = append(c.ops, patternOp{Kind: opReverse, Start: 1<<16 - 1, End: 0})
+	if got := c.Generate(20); got[0] != 19 || got[19] != 0 {
+		t.Errorf("reverse should swap the ends, got %v", got)
+	}
+}
+
+func TestNintherKillerGrowth(t *testing.T) {
+	c := &generativeIndividual{
+		ops: []patternOp{
+			{Kind: opNintherKiller, Start: 0, End: 1<<16 - 1},
+		},
+	}
+	eval := &TargetEvaluator{Target: mustLookupSortTarget("go1.5/sort.Sort"), Model: mustLookupCostModel("")}
+	random := eval.Cost(rand.New(rand.NewSource(1)).Perm(16000))
+	// Comparisons/(n·log2 n) keeps rising, so the cost grows faster than n·log2 n
+	last := 0.0
+	for _, n := range []int{1000, 4000, 16000} {
+		intensity := Intensity(eval.Cost(c.Generate(n)), n)
+		if intensity <= last {
+			t.Errorf("intensity fell to %.3f at n=%d", intensity, n)
+		}
+		last = intensity
+	}
+	if last < 2*Intensity(random, 16000) {
+		t.Errorf("intensity %.3f is barely worse than random", last)
+	}
+}
+
+func TestGenerativeFactorySpawn(t *testing.T) {
+	f := &GenerativeFactory{
+		Sizes: []int{10, 100},
+	}
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 100; i++ {
+		c := f.Spawn(r).(*generativeIndividual)
+		for _, n := range []int{0, 1, 10, 1000} {
+			vals := c.Generate(n)
+			if len(vals) != n {
+				t.Fatalf("%s made %d values, not %d", c, len(vals), n)
+			}
+			// Every op but quantize keeps a permutation, and quantize only lowers values
+			sort.Ints(vals)
+			for j, v := range vals {
+				if v > j {
+					t.Fatalf("%s made value %d at rank %d", c, v, j)
+				}
+			}
+		}
+		fitness := c.Fitness()
+		c.Simplify()
+		cached := c.Fitness()
+		c.fitness = nil
+		// Removing an op may help, but never hurts, and the cached fitness is the simplified program's
+		if cached < fitness || c.Fitness() != cached {
+			t.Errorf("simplify took fitness from %d to %d, cached as %d", fitness, c.Fitness(), cached)
+		}
+	}
+}
@@ -0,0 +1,496 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package tracesort is an instrumented copy of the go127 pdqsort.  It records what the sort did with an input: the
+// recursion tree, each pivot and how well it partitioned, fallbacks to heapsort and insertion sort, and how many
+// comparisons each phase made, to explain why an input is slow.  Only pdqsort and Sort differ from go127.
+package tracesort
+
+import (
+	"math/bits"
+	"sort"
+)
+
+// Interface is sort.Interface
+type Interface = sort.Interface
+
+// Phase is a part of pdqsort that comparisons are charged to
+type Phase string
+
+const (
+	PhaseChoosePivot          Phase = "choosePivot"
+	PhaseReverse              Phase = "reverseRange"
+	PhasePartialInsertionSort Phase = "partialInsertionSort"
+	PhasePartitionEqual       Phase = "partitionEqual"
+	PhasePartition            Phase = "partition"
+	PhaseInsertionSort        Phase = "insertionSort"
+	PhaseHeapSort             Phase = "heapSort"
+	PhaseBreakPatterns        Phase = "breakPatterns"
+)
+
+type PhaseCount struct {
+	Calls       int `json:"calls"`
+	Comparisons int `json:"comparisons"`
+	Swaps       int `json:"swaps"`
+}
+
+// Step is one pass of pdqsort's loop over data[Lo:Hi]
+type Step struct {
+	Lo    int `json:"lo"`
+	Hi    int `json:"hi"`
+	Limit int `json:"limit"`
+	// Action is the phase that finished the step: insertionSort, heapSort or partialInsertionSort, which finish
+	// the range, or partitionEqual and partition, which split it
+	Action        Phase `json:"action"`
+	BrokePatterns bool  `json:"brokePatterns,omitempty"`
+	// Pivot is the index choosePivot picked, and Hint what it guessed about the order of the range
+	Pivot    int    `json:"pivot,omitempty"`
+	Hint     string `json:"hint,omitempty"`
+	Reversed bool   `json:"reversed,omitempty"`
+	// Mid is where the pivot ended up.  Balance is the smaller side over the length; pdqsort calls below 1/8
+	// unbalanced
+	Mid                int     `json:"mid,omitempty"`
+	Balance            float64 `json:"balance,omitempty"`
+	AlreadyPartitioned bool    `json:"alreadyPartitioned,omitempty"`
+	Comparisons        int     `json:"comparisons"`
+	// Recursed is the call made on the smaller side of a partition
+	Recursed *Node `json:"recursed,omitempty"`
+}
+
+// Node is one call of pdqsort
+type Node struct {
+	Depth int     `json:"depth"`
+	Steps []*Step `json:"steps"`
+}
+
+type Trace struct {
+	N           int                  `json:"n"`
+	Comparisons int                  `json:"comparisons"`
+	Swaps       int                  `json:"swaps"`
+	Phases      map[Phase]PhaseCount `json:"phases"`
+	Root        *Node                `json:"root"`
+}
+
+// traced charges every comparison and swap to the current phase
+type traced struct {
+	Interface
+	trace *Trace
+	phase Phase
+}
+
+func (t *traced) Less(i, j int) bool {
+	t.trace.Comparisons++
+	p := t.trace.Phases[t.phase]
+	p.Comparisons++
+	t.trace.Phases[t.phase] = p
+	return t.Interface.Less(i, j)
+}
+
+func (t *traced) Swap(i, j int) {
+	t.trace.Swaps++
+	p := t.trace.Phases[t.phase]
+	p.Swaps++
+	t.trace.Phases[t.phase] = p
+	t.Interface.Swap(i, j)
+}
+
+func (t *traced) enter(phase Phase) {
+	t.phase = phase
+	p := t.trace.Phases[phase]
+	p.Calls++
+	t.trace.Phases[phase] = p
+}
+
+var hintNames = map[sortedHint]string{
+	unknownHint:    "unknown",
+	increasingHint: "increasing",
+	decreasingHint: "decreasing",
+}
+
+// Sort sorts data like go127.Sort, and returns a trace of how
+func Sort(data Interface) *Trace {
+	n := data.Len()
+	trace := &Trace{
+		N:      n,
+		Phases: make(map[Phase]PhaseCount),
+		Root:   &Node{},
+	}
+	limit := bits.Len(uint(n))
+	pdqsort(&traced{Interface: data, trace: trace}, 0, n, limit, trace.Root)
+	return trace
+}
+
+// pdqsort sorts data[a:b].
+// The algorithm based on pattern-defeating quicksort(pdqsort), but without the optimizations from BlockQuicksort.
+// pdqsort paper: https://arxiv.org/pdf/2106.05123.pdf
+// C++ implementation: https://github.com/orlp/pdqsort
+// Rust implementation: https://docs.rs/pdqsort/latest/pdqsort/
+// limit is the number of allowed bad (very unbalanced) pivots before falling back to heapsort.
+func pdqsort(data *traced, a, b, limit int, node *Node) {
+	const maxInsertion = 12
+
+	var (
+		wasBalanced    = true // whether the last partitioning was reasonably balanced
+		wasPartitioned = true // whether the slice was already partitioned
+	)
+
+	for {
+		length := b - a
+		step := &Step{Lo: a, Hi: b, Limit: limit}
+		node.Steps = append(node.Steps, step)
+		before := data.trace.Comparisons
+
+		if length <= maxInsertion {
+			data.enter(PhaseInsertionSort)
+			insertionSort(data, a, b)
+			step.Action = PhaseInsertionSort
+			step.Comparisons = data.trace.Comparisons - before
+			return
+		}
+
+		// Fall back to heapsort if too many bad choices were made.
+		if limit == 0 {
+			data.enter(PhaseHeapSort)
+			heapSort(data, a, b)
+			step.Action = PhaseHeapSort
+			step.Comparisons = data.trace.Comparisons - before
+			return
+		}
+
+		// If the last partitioning was imbalanced, we need to breaking patterns.
+		if !wasBalanced {
+			data.enter(PhaseBreakPatterns)
+			breakPatterns(data, a, b)
+			step.BrokePatterns = true
+			limit--
+		}
+
+		data.enter(PhaseChoosePivot)
+		pivot, hint := choosePivot(data, a, b)
+		step.Hint = hintNames[hint]
+		if hint == decreasingHint {
+			data.enter(PhaseReverse)
+			reverseRange(data, a, b)
+			step.Reversed = true
+			// The chosen pivot was pivot-a elements after the start of the array.
+			// After reversing it is pivot-a elements before the end of the array.
+			// The idea came from Rust's implementation.
+			pivot = (b - 1) - (pivot - a)
+			hint = increasingHint
+		}
+		step.Pivot = pivot
+
+		// The slice is likely already sorted.
+		if wasBalanced && wasPartitioned && hint == increasingHint {
+			data.enter(PhasePartialInsertionSort)
+			if partialInsertionSort(data, a, b) {
+				step.Action = PhasePartialInsertionSort
+				step.Comparisons = data.trace.Comparisons - before
+				return
+			}
+		}
+
+		// Probably the slice contains many duplicate elements, partition the slice into
+		// elements equal to and elements greater than the pivot.
+		// The check is charged to partitionEqual, but only counts as a call when it passes
+		data.phase = PhasePartitionEqual
+		if a > 0 && !data.Less(a-1, pivot) {
+			data.enter(PhasePartitionEqual)
+			mid := partitionEqual(data, a, b, pivot)
+			step.Action = PhasePartitionEqual
+			step.Mid = mid
+			step.Comparisons = data.trace.Comparisons - before
+			a = mid
+			continue
+		}
+
+		data.enter(PhasePartition)
+		mid, alreadyPartitioned := partition(data, a, b, pivot)
+		wasPartitioned = alreadyPartitioned
+		step.Action = PhasePartition
+		step.Mid = mid
+		step.AlreadyPartitioned = alreadyPartitioned
+		step.Comparisons = data.trace.Comparisons - before
+
+		leftLen, rightLen := mid-a, b-mid
+		step.Balance = float64(min(leftLen, rightLen)) / float64(length)
+		balanceThreshold := length / 8
+		child := &Node{Depth: node.Depth + 1}
+		step.Recursed = child
+		if leftLen < rightLen {
+			wasBalanced = leftLen >= balanceThreshold
+			pdqsort(data, a, mid, limit, child)
+			a = mid + 1
+		} else {
+			wasBalanced = rightLen >= balanceThreshold
+			pdqsort(data, mid+1, b, limit, child)
+			b = mid
+		}
+	}
+}
+
+type sortedHint int // hint for pdqsort when choosing the pivot
+
+const (
+	unknownHint sortedHint = iota
+	increasingHint
+	decreasingHint
+)
+
+// xorshift paper: https://www.jstatsoft.org/article/view/v008i14/xorshift.pdf
+type xorshift uint64
+
+func (r *xorshift) Next() uint64 {
+	*r ^= *r << 13
+	*r ^= *r >> 7
+	*r ^= *r << 17
+	return uint64(*r)
+}
+
+func nextPowerOfTwo(length int) uint {
+	shift := uint(bits.Len(uint(length)))
+	return uint(1 << shift)
+}
+
+// insertionSort sorts data[a:b] using insertion sort.
+func insertionSort(data Interface, a, b int) {
+	for i := a + 1; i < b; i++ {
+		for j := i; j > a && data.Less(j, j-1); j-- {
+			data.Swap(j, j-1)
+		}
+	}
+}
+
+// siftDown implements the heap property on data[lo:hi].
+// first is an offset into the array where the root of the heap lies.
+func siftDown(data Interface, lo, hi, first int) {
+	root := lo
+	for {
+		child := 2*root + 1
+		if child >= hi {
+			break
+		}
+		if child+1 < hi && data.Less(first+child, first+child+1) {
+			child++
+		}
+		if !data.Less(first+root, first+child) {
+			return
+		}
+		data.Swap(first+root, first+child)
+		root = child
+	}
+}
+
+func heapSort(data Interface, a, b int) {
+	first := a
+	lo := 0
+	hi := b - a
+
+	// Build heap with greatest element at top.
+	for i := (hi - 1) / 2; i >= 0; i-- {
+		siftDown(data, i, hi, first)
+	}
+
+	// Pop elements, largest first, into end of data.
+	for i := hi - 1; i >= 0; i-- {
+		data.Swap(first, first+i)
+		siftDown(data, lo, i, first)
+	}
+}
+
+// partition does one quicksort partition.
+// Let p = data[pivot]
+// Moves elements in data[a:b] around, so that data[i]<p and data[j]>=p for i<newpivot and j>newpivot.
+// On return, data[newpivot] = p
+func partition(data Interface, a, b, pivot int) (newpivot int, alreadyPartitioned bool) {
+	data.Swap(a, pivot)
+	i, j := a+1, b-1 // i and j are inclusive of the elements remaining to be partitioned
+
+	for i <= j && data.Less(i, a) {
+		i++
+	}
+	for i <= j && !data.Less(j, a) {
+		j--
+	}
+	if i > j {
+		data.Swap(j, a)
+		return j, true
+	}
+	data.Swap(i, j)
+	i++
+	j--
+
+	for {
+		for i <= j && data.Less(i, a) {
+			i++
+		}
+		for i <= j && !data.Less(j, a) {
+			j--
+		}
+		if i > j {
+			break
+		}
+		data.Swap(i, j)
+		i++
+		j--
+	}
+	data.Swap(j, a)
+	return j, false
+}
+
+// partitionEqual partitions data[a:b] into elements equal to data[pivot] followed by elements greater than data[pivot].
+// It assumed that data[a:b] does not contain elements smaller than the data[pivot].
+func partitionEqual(data Interface, a, b, pivot int) (newpivot int) {
+	data.Swap(a, pivot)
+	i, j := a+1, b-1 // i and j are inclusive of the elements remaining to be partitioned
+
+	for {
+		for i <= j && !data.Less(a, i) {
+			i++
+		}
+		for i <= j && data.Less(a, j) {
+			j--
+		}
+		if i > j {
+			break
+		}
+		data.Swap(i, j)
+		i++
+		j--
+	}
+	return i
+}
+
+// partialInsertionSort partially sorts a slice, returns true if the slice is sorted at the end.
+func partialInsertionSort(data Interface, a, b int) bool {
+	const (
+		maxSteps         = 5  // maximum number of adjacent out-of-order pairs that will get shifted
+		shortestShifting = 50 // don't shift any elements on short arrays
+	)
+	i := a + 1
+	for j := 0; j < maxSteps; j++ {
+		for i < b && !data.Less(i, i-1) {
+			i++
+		}
+
+		if i == b {
+			return true
+		}
+
+		if b-a < shortestShifting {
+			return false
+		}
+
+		data.Swap(i, i-1)
+
+		// Shift the smaller one to the left.
+		if i-a >= 2 {
+			for j := i - 1; j >= 1; j-- {
+				if !data.Less(j, j-1) {
+					break
+				}
+				data.Swap(j, j-1)
+			}
+		}
+		// Shift the greater one to the right.
+		if b-i >= 2 {
+			for j := i + 1; j < b; j++ {
+				if !data.Less(j, j-1) {
+					break
+				}
+				data.Swap(j, j-1)
+			}
+		}
+	}
+	return false
+}
+
+// breakPatterns scatters some elements around in an attempt to break some patterns
+// that might cause imbalanced partitions in quicksort.
+func breakPatterns(data Interface, a, b int) {
+	length := b - a
+	if length >= 8 {
+		random := xorshift(length)
+		modulus := nextPowerOfTwo(length)
+
+		for idx := a + (length/4)*2 - 1; idx <= a+(length/4)*2+1; idx++ {
+			other := int(uint(random.Next()) & (modulus - 1))
+			if other >= length {
+				other -= length
+			}
+			data.Swap(idx, a+other)
+		}
+	}
+}
+
+// [0,8): chooses a static pivot.
+// [8,shortestNinther): uses the simple median-of-three method.
+// [shortestNinther,∞): uses the Tukey ninther method.
+func choosePivot(data Interface, a, b int) (pivot int, hint sortedHint) {
+	const (
+		shortestNinther = 50
+		maxSwaps        = 4 * 3
+	)
+
+	l := b - a
+
+	var (
+		swaps int
+		i     = a + l/4*1
+		j     = a + l/4*2
+		k     = a + l/4*3
+	)
+
+	if l >= 8 {
+		if l >= shortestNinther {
+			// Tukey ninther method, the idea came from Rust's implementation.
+			i = medianAdjacent(data, i, &swaps)
+			j = medianAdjacent(data, j, &swaps)
+			k = medianAdjacent(data, k, &swaps)
+		}
+		// Find the median among i, j, k and stores it into j.
+		j = median(data, i, j, k, &swaps)
+	}
+
+	switch swaps {
+	case 0:
+		return j, increasingHint
+	case maxSwaps:
+		return j, decreasingHint
+	default:
+		return j, unknownHint
+	}
+}
+
+// order2 returns x,y where data[x] <= data[y], where x,y=a,b or x,y=b,a.
+func order2(data Interface, a, b int, swaps *int) (int, int) {
+	if data.Less(b, a) {
+		*swaps++
+		return b, a
+	}
+	return a, b
+}
+
+// median returns x where data[x] is the median of data[a],data[b],data[c], where x is a, b, or c.
+func median(data Interface, a, b, c int, swaps *int) int {
+	a, b = order2(data, a, b, swaps)
+	b, c = order2(data, b, c, swaps)
+	a, b = order2(data, a, b, swaps)
+	return b
+}
+
+// medianAdjacent finds the median of data[a - 1], data[a], data[a + 1] and stores the index into a.
+func medianAdjacent(data Interface, a int, swaps *int) int {
+	return median(data, a-1, a, a+1, swaps)
+}
+
+func reverseRange(data Interface, a, b int) {
+	i := a
+	j := b - 1
+	for i < j {
+		data.Swap(i, j)
+		i++
+		j--
+	}
+}
@@ -0,0 +1,56 @@
+package tracesort
+
+import (
+	"encoding/json"
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/cep21/geneticsort/internal/arraysort/gosort/go127"
+)
+
+type countingInts struct {
+	sort.IntSlice
+	comparisons *int
+}
+
+func (c countingInts) Less(i, j int) bool {
+	*c.comparisons++
+	return c.IntSlice.Less(i, j)
+}
+
+func TestSortMatchesGo127(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for _, n := range []int{0, 1, 12, 13, 100, 1000, 5000} {
+		for _, unique := range []int{3, n + 1} {
+			vals := make([]int, n)
+			for i := range vals {
+				vals[i] = r.Intn(unique)
+			}
+			traced := append([]int(nil), vals...)
+			trace := Sort(sort.IntSlice(traced))
+			var comparisons int
+			go127.Sort(countingInts{IntSlice: vals, comparisons: &comparisons})
+			if !sort.IntsAreSorted(traced) {
+				t.Fatalf("n=%d was not sorted", n)
+			}
+			if trace.Comparisons != comparisons {
+				t.Errorf("n=%d: traced %d comparisons, go127 made %d", n, trace.Comparisons, comparisons)
+			}
+			phaseComparisons := 0
+			for _, p := range trace.Phases {
+				phaseComparisons += p.Comparisons
+			}
+			stepComparisons := 0
+			trace.Root.Walk(func(depth int, s *Step) {
+				stepComparisons += s.Comparisons
+			})
+			if phaseComparisons != comparisons || stepComparisons != comparisons {
+				t.Errorf("n=%d: phases have %d comparisons and steps %d, not %d", n, phaseComparisons, stepComparisons, comparisons)
+			}
+			if _, err := json.Marshal(trace); err != nil {
+				t.Error(err)
+			}
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package tracesort
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// Walk calls fn on every step, parents before children
+func (n *Node) Walk(fn func(depth int, s *Step)) {
+	for _, s := range n.Steps {
+		fn(n.Depth, s)
+		if s.Recursed != nil {
+			s.Recursed.Walk(fn)
+		}
+	}
+}
+
+// String summarizes the trace: where comparisons went, how balanced partitions were, and what pdqsort fell back to
+func (t *Trace) String() string {
+	var s strings.Builder
+	intensity := 0.0
+	if t.N > 1 {
+		intensity = float64(t.Comparisons) / (float64(t.N) * math.Log2(float64(t.N)))
+	}
+	mustPrint(fmt.Fprintf(&s, "n=%d comparisons=%d (%.3f·n·log2 n) swaps=%d\n", t.N, t.Comparisons, intensity, t.Swaps))
+
+	phases := make([]Phase, 0, len(t.Phases))
+	for p := range t.Phases {
+		phases = append(phases, p)
+	}
+	sort.Slice(phases, func(i, j int) bool {
+		return t.Phases[phases[i]].Comparisons > t.Phases[phases[j]].Comparisons
+	})
+	mustPrint(fmt.Fprintf(&s, "%-22s %8s %12s %7s %12s\n", "phase", "calls", "comparisons", "share", "swaps"))
+	for _, p := range phases {
+		c := t.Phases[p]
+		share := 0.0
+		if t.Comparisons != 0 {
+			share = 100 * float64(c.Comparisons) / float64(t.Comparisons)
+		}
+		mustPrint(fmt.Fprintf(&s, "%-22s %8d %12d %6.1f%% %12d\n", p, c.Calls, c.Comparisons, share, c.Swaps))
+	}
+
+	var partitions, unbalanced, alreadyPartitioned, reversed, brokePatterns, heapSorts, heapSorted, maxDepth int
+	var balance float64
+	t.Root.Walk(func(depth int, step *Step) {
+		maxDepth = max(maxDepth, depth)
+		if step.BrokePatterns {
+			brokePatterns++
+		}
+		if step.Reversed {
+			reversed++
+		}
+		switch step.Action {
+		case PhasePartition:
+			partitions++
+			balance += step.Balance
+			if step.Balance < 1.0/8 {
+				unbalanced++
+			}
+			if step.AlreadyPartitioned {
+				alreadyPartitioned++
+			}
+		case PhaseHeapSort:
+			heapSorts++
+			heapSorted += step.Hi - step.Lo
+		}
+	})
+	if partitions != 0 {
+		balance /= float64(partitions)
+	}
+	mustPrint(fmt.Fprintf(&s, "recursion depth %d, %d partitions with mean balance %.3f, %d unbalanced, %d already partitioned\n",
+		maxDepth, partitions, balance, unbalanced, alreadyPartitioned))
+	mustPrint(fmt.Fprintf(&s, "%d reversed ranges, %d pattern breaks, %d heapsort fallbacks covering %d elements\n",
+		reversed, brokePatterns, heapSorts, heapSorted))
+	return s.String()
+}
+
+func mustPrint(_ int, err error) {
+	if err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,232 @@
+package arraysort
+
+import (
+	"encoding/binary"
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+
+	"github.com/cep21/geneticsort/genetic"
+	"github.com/cep21/geneticsort/internal/arraysort/gosort/go127"
+)
+
+// Record is a struct sorted by Key.  Payload is its position in the input, so a stable sort must keep equal keys in
+// Payload order.
+type Record struct {
+	Key     int
+	Payload int
+}
+
+// RecordSort stably sorts vals in place by Key, comparing keys only through c.  Swaps are only counted by sorts that
+// swap records through sort.Interface.
+type RecordSort func(vals []Record, c *Counter)
+
+type countingRecords struct {
+	vals []Record
+	c    *Counter
+}
+
+func (c countingRecords) Len() int           { return len(c.vals) }
+func (c countingRecords) Less(i, j int) bool { return c.c.Less(c.vals[i].Key, c.vals[j].Key) }
+func (c countingRecords) Swap(i, j int) {
+	c.c.Swaps++
+	c.vals[i], c.vals[j] = c.vals[j], c.vals[i]
+}
+
+const defaultRecordSortTarget = "sort.SliceStable"
+
+var recordSortTargets = map[string]RecordSort{
+	"sort.SliceStable": func(vals []Record, c *Counter) {
+		sort.SliceStable(vals, func(i, j int) bool {
+			return c.Less(vals[i].Key, vals[j].Key)
+		})
+	},
+	"sort.Stable": func(vals []Record, c *Counter) {
+		sort.Stable(countingRecords{vals: vals, c: c})
+	},
+	"go1.27/sort.Stable": func(vals []Record, c *Counter) {
+		go127.Stable(countingRecords{vals: vals, c: c})
+	},
+	"slices.SortStableFunc": func(vals []Record, c *Counter) {
+		slices.SortStableFunc(vals, func(a, b Record) int {
+			return c.Compare(a.Key, b.Key)
+		})
+	},
+}
+
+// swappingRecordSorts are the record targets that count swaps, by swapping through countingRecords
+var swappingRecordSorts = map[string]bool{
+	"sort.Stable":        true,
+	"go1.27/sort.Stable": true,
+}
+
+func RegisterRecordSortTarget(name string, s RecordSort) {
+	recordSortTargets[name] = s
+	delete(swappingRecordSorts, name)
+}
+
+// RegisterSwappingRecordSortTarget is RegisterRecordSortTarget for a sort that counts each swap in Counter.Swaps, so
+// it can be scored by swaps
+func RegisterSwappingRecordSortTarget(name string, s RecordSort) {
+	recordSortTargets[name] = s
+	swappingRecordSorts[name] = true
+}
+
+// CheckRecordCost returns an error if records sorted by target can't be scored by cost, like CheckCostModel does for
+// int arrays
+func CheckRecordCost(target string, cost string) error {
+	_, err := recordCost(target, cost)
+	return err
+}
+
+// recordCost is the counter based cost model named cost, if target can be scored by it
+func recordCost(target string, cost string) (*counterCost, error) {
+	if _, err := LookupRecordSortTarget(target); err != nil {
+		return nil, err
+	}
+	if target == "" {
+		target = defaultRecordSortTarget
+	}
+	model, err := LookupCostModel(cost)
+	asCounter, ok := model.(*counterCost)
+	if err != nil || !ok {
+		return nil, fmt.Errorf("unknown record cost %q: valid costs are comparisons, swaps or comparisons+swaps", cost)
+	}
+	if asCounter.swaps && !swappingRecordSorts[target] {
+		var swapping []string
+		for _, name := range RecordSortTargetNames() {
+			if swappingRecordSorts[name] {
+				swapping = append(swapping, name)
+			}
+		}
+		return nil, fmt.Errorf("record sort target %q doesn't count swaps, so can't be scored by %s: targets that do are %v", target, asCounter.name, swapping)
+	}
+	return asCounter, nil
+}
+
+func LookupRecordSortTarget(name string) (RecordSort, error) {
+	if name == "" {
+		name = defaultRecordSortTarget
+	}
+	s, exists := recordSortTargets[name]
+	if !exists {
+		return nil, fmt.Errorf("unknown record sort target %q: valid targets are %v", name, RecordSortTargetNames())
+	}
+	return s, nil
+}
+
+func RecordSortTargetNames() []string {
+	ret := make([]string, 0, len(recordSortTargets))
+	for name := range recordSortTargets {
+		ret = append(ret, name)
+	}
+	sort.Strings(ret)
+	return ret
+}
+
+// isStable is whether sorted, the output of a stable sort, kept equal keys in input order
+func isStable(sorted []Record) bool {
+	for i := 1; i < len(sorted); i++ {
+		if sorted[i-1].Key > sorted[i].Key || sorted[i-1].Key == sorted[i].Key && sorted[i-1].Payload > sorted[i].Payload {
+			return false
+		}
+	}
+	return true
+}
+
+// RecordSortingFactory spawns slices of records with few distinct keys, for attacking stable sorts
+type RecordSortingFactory struct {
+	IndividualSize int
+	// Keys is how many distinct keys records draw from.  Defaults to IndividualSize/8, so keys repeat
+	Keys int
+	// Target is the name of a registered RecordSort.  Defaults to sort.SliceStable
+	Target string
+	// Cost is comparisons, swaps or comparisons+swaps.  Defaults to comparisons
+	Cost string
+
+	kind *sliceKind[int]
+}
+
+var _ genetic.ChromosomeFactory = &RecordSortingFactory{}
+
+func (f *RecordSortingFactory) keys() int {
+	if f.Keys <= 0 {
+		return max(f.IndividualSize/8, 1)
+	}
+	return f.Keys
+}
+
+func (f *RecordSortingFactory) Family() string {
+	target, cost := f.Target, f.Cost
+	if target == "" {
+		target = defaultRecordSortTarget
+	}
+	if cost == "" {
+		cost = defaultCostModel
+	}
+	return fmt.Sprintf("records-sort-%d-%d-%s-%s", f.IndividualSize, f.keys(), target, cost)
+}
+
+// sliceKind's values are keys.  Records are made from them, with payloads, when they are sorted.
+func (f *RecordSortingFactory) sliceKind() *sliceKind[int] {
+	if f.kind != nil {
+		return f.kind
+	}
+	target, err := LookupRecordSortTarget(f.Target)
+	if err != nil {
+		panic(err)
+	}
+	model, err := recordCost(f.Target, f.Cost)
+	if err != nil {
+		panic(err)
+	}
+	targetName := f.Target
+	if targetName == "" {
+		targetName = defaultRecordSortTarget
+	}
+	countsSwaps := swappingRecordSorts[targetName]
+	keys := f.keys()
+	counted := func(vals []int) (Counter, bool) {
+		records := make([]Record, len(vals))
+		for i, key := range vals {
+			records[i] = Record{Key: key, Payload: i}
+		}
+		var c Counter
+		target(records, &c)
+		return c, isStable(records)
+	}
+	f.kind = &sliceKind[int]{
+		random: func(r genetic.Rand) int {
+			return r.Intn(keys)
+		},
+		cost: func(vals []int) int {
+			c, _ := counted(vals)
+			return model.cost(c)
+		},
+		metrics: func(vals []int) map[string]int {
+			c, stable := counted(vals)
+			ret := map[string]int{
+				"comparisons": c.Comparisons,
+				"unstable":    0,
+			}
+			// Other targets never count swaps, and 0 would look like a measurement
+			if countsSwaps {
+				ret["swaps"] = c.Swaps
+			}
+			if !stable {
+				ret["unstable"] = 1
+			}
+			return ret
+		},
+		format: strconv.Itoa,
+		bytes: func(v int) []byte {
+			return binary.LittleEndian.AppendUint64(nil, uint64(v))
+		},
+	}
+	return f.kind
+}
+
+func (f *RecordSortingFactory) Spawn(r genetic.Rand) genetic.Chromosome {
+	return newSliceIndividual(f.sliceKind(), f.IndividualSize, r)
+}
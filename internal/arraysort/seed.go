@@ -0,0 +1,142 @@
+package arraysort
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+
+	"github.com/cep21/geneticsort/genetic"
+)
+
+// SeedPattern builds a structured array of n values.  target is the sort under attack.
+type SeedPattern func(n int, r genetic.Rand, target SortTarget) []int
+
+var seedPatterns = map[string]SeedPattern{
+	"sorted": func(n int, r genetic.Rand, target SortTarget) []int {
+		ret := make([]int, n)
+		for i := range ret {
+			ret[i] = i
+		}
+		return ret
+	},
+	"reversed": func(n int, r genetic.Rand, target SortTarget) []int {
+		ret := make([]int, n)
+		for i := range ret {
+			ret[i] = n - i
+		}
+		return ret
+	},
+	"organ-pipe": func(n int, r genetic.Rand, target SortTarget) []int {
+		ret := make([]int, n)
+		for i := range ret {
+			ret[i] = min(i, n-1-i)
+		}
+		return ret
+	},
+	"sawtooth": func(n int, r genetic.Rand, target SortTarget) []int {
+		period := 2 + r.Intn(max(n/2, 1))
+		ret := make([]int, n)
+		for i := range ret {
+			ret[i] = i % period
+		}
+		return ret
+	},
+	"few-unique": func(n int, r genetic.Rand, target SortTarget) []int {
+		unique := 2 + r.Intn(7)
+		ret := make([]int, n)
+		for i := range ret {
+			ret[i] = r.Intn(unique)
+		}
+		return ret
+	},
+	"median-of-3-killer": medianOfThreeKiller,
+	"mcilroy": func(n int, r genetic.Rand, target SortTarget) []int {
+		return Adversary(target, n)
+	},
+}
+
+// medianOfThreeKiller is Musser's sequence that makes median of three quicksort quadratic.  See "Introspective
+// Sorting and Selection Algorithms", 1997.  The sequence needs a multiple of 4 values; any others come last, in order.
+func medianOfThreeKiller(n int, r genetic.Rand, target SortTarget) []int {
+	ret := make([]int, n)
+	k := n / 4 * 2
+	for i := 1; i <= k; i++ {
+		if i%2 == 1 {
+			ret[i-1] = i
+			ret[i] = k + i
+		}
+		ret[k+i-1] = 2 * i
+	}
+	for i := 2 * k; i < n; i++ {
+		ret[i] = i + 1
+	}
+	return ret
+}
+
+// PatternGenerator generates the named seed pattern at any length.  Patterns that draw random numbers always draw the
+// same ones.
+func PatternGenerator(name string, target SortTarget) (Generator, error) {
+	p, exists := seedPatterns[name]
+	if !exists {
+		return nil, fmt.Errorf("unknown seed pattern %q: valid patterns are %v", name, SeedPatternNames())
+	}
+	return GeneratorFunc(func(n int) []int {
+		return p(n, rand.New(rand.NewSource(int64(n))), target)
+	}), nil
+}
+
+// RegisterSeedPattern makes p available to SeedingFactory by name
+func RegisterSeedPattern(name string, p SeedPattern) {
+	seedPatterns[name] = p
+}
+
+func SeedPatternNames() []string {
+	ret := make([]string, 0, len(seedPatterns))
+	for name := range seedPatterns {
+		ret = append(ret, name)
+	}
+	sort.Strings(ret)
+	return ret
+}
+
+// SeedingFactory spawns Fraction of a population from structured patterns that are known to trouble sorts, so the
+// algorithm doesn't start from pure noise.  The rest are spawned by Factory.
+type SeedingFactory struct {
+	Factory  *ArraySortingFactory
+	Fraction float64
+	// Patterns are the names of registered SeedPatterns to pick from.  Defaults to all of them
+	Patterns []string
+}
+
+var _ genetic.ChromosomeFactory = &SeedingFactory{}
+
+// Family is the family of Factory: seeding changes where the search starts, not what it measures
+func (s *SeedingFactory) Family() string {
+	return s.Factory.Family()
+}
+
+func (s *SeedingFactory) patterns() []string {
+	if len(s.Patterns) == 0 {
+		return SeedPatternNames()
+	}
+	return s.Patterns
+}
+
+func (s *SeedingFactory) pattern(name string, r genetic.Rand) []int {
+	p, exists := seedPatterns[name]
+	if !exists {
+		panic(fmt.Sprintf("unknown seed pattern %q: valid patterns are %v", name, SeedPatternNames()))
+	}
+	return p(s.Factory.IndividualSize, r, mustLookupSortTarget(s.Factory.Target))
+}
+
+func (s *SeedingFactory) Spawn(r genetic.Rand) genetic.Chromosome {
+	if s.Fraction <= 0 || r.Float64() >= s.Fraction {
+		return s.Factory.Spawn(r)
+	}
+	patterns := s.patterns()
+	return &arraySortingIndividual{
+		vals: s.pattern(patterns[r.Intn(len(patterns))], r),
+		eval: s.Factory.evaluator(),
+	}
+}
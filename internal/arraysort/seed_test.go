@@ -0,0 +1,55 @@
+package arraysort
+
+import (
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+func TestSeedPatterns(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	target := mustLookupSortTarget("")
+	for _, name := range SeedPatternNames() {
+		for _, n := range []int{1, 2, 7, 100} {
+			if got := len(seedPatterns[name](n, r, target)); got != n {
+				t.Errorf("%s made %d values, not %d", name, got, n)
+			}
+		}
+	}
+	for n := 0; n < 30; n++ {
+		got := medianOfThreeKiller(n, r, target)
+		sort.Ints(got)
+		for i, v := range got {
+			if v != i+1 {
+				t.Fatalf("median of 3 killer for %d is not a permutation of 1..%d", n, n)
+			}
+		}
+	}
+	// Musser's sequence for n=20, from the paper
+	want := []int{1, 11, 3, 13, 5, 15, 7, 17, 9, 19, 2, 4, 6, 8, 10, 12, 14, 16, 18, 20}
+	got := medianOfThreeKiller(20, r, target)
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("median of 3 killer: got %v want %v", got, want)
+		}
+	}
+}
+
+func TestSeedingFactory(t *testing.T) {
+	s := &SeedingFactory{
+		Factory: &ArraySortingFactory{
+			IndividualSize: 50,
+		},
+		Fraction: 1,
+		Patterns: []string{"sorted"},
+	}
+	c := s.Spawn(rand.New(rand.NewSource(1))).(*arraySortingIndividual)
+	for i, v := range c.vals {
+		if v != i {
+			t.Fatalf("expected a sorted seed, got %v", c.vals)
+		}
+	}
+	if s.Family() != s.Factory.Family() {
+		t.Errorf("seeding should not change the family")
+	}
+}
@@ -0,0 +1,178 @@
+package arraysort
+
+import (
+	"math"
+	"sort"
+
+	"github.com/cep21/geneticsort/genetic"
+	"github.com/cep21/geneticsort/internal/encoding"
+)
+
+// Shrinker reduces an adversarial array to a small one that still makes the sort do disproportionate work, so it can
+// be reported to a sort's maintainers.  It uses delta debugging (Zeller's ddmin) to remove runs of elements, then
+// merges values together, and finally rank compresses what is left.
+type Shrinker struct {
+	// Evaluator scores candidates.  Defaults to the chromosome's own, or sort.Slice comparisons
+	Evaluator Evaluator
+	// Threshold, if set, is the smallest Intensity a shrunk array may have.  Otherwise shrunk arrays must keep the
+	// ExcessGrowth of the input.  A quadratic input's intensity falls as it shrinks, while its excess growth stays
+	// near 1.  Inputs with less than MinExcessGrowth aren't adversarial, so aren't shrunk without a Threshold.
+	Threshold float64
+	// Keep, if set, replaces both checks.  It must not modify vals.
+	Keep func(vals []int) bool
+}
+
+// Intensity is cost/(n·log2 n): how much worse than a typical n·log2 n sort vals are
+func Intensity(cost int, n int) float64 {
+	if n < 2 {
+		return 0
+	}
+	return float64(cost) / (float64(n) * math.Log2(float64(n)))
+}
+
+// MinExcessGrowth is the least ExcessGrowth Shrinker treats as adversarial by default.  Random arrays of 100 or more
+// values stay well under it.
+const MinExcessGrowth = .5
+
+// ExcessGrowth is how much faster than n·log2 n cost grows from half of vals to vals, as a power of n, so random
+// arrays are near 0 and quadratic ones near 1.  Growth is measured between two sizes, rather than from a single
+// cost, so the constant factors that dominate small arrays cancel out.  The halves are every other value, averaged
+// over both offsets.
+func ExcessGrowth(eval Evaluator, vals []int) float64 {
+	n := len(vals)
+	if n < 4 {
+		return 0
+	}
+	even := make([]int, 0, (n+1)/2)
+	odd := make([]int, 0, n/2)
+	for i, v := range vals {
+		if i%2 == 0 {
+			even = append(even, v)
+		} else {
+			odd = append(odd, v)
+		}
+	}
+	halfCost := math.Max(float64(eval.Cost(even)+eval.Cost(odd))/2, 1)
+	nlgn := func(n float64) float64 {
+		return n * math.Log2(n)
+	}
+	return math.Log2(math.Max(float64(eval.Cost(vals)), 1)/halfCost) - math.Log2(nlgn(float64(n))/nlgn(float64(n)/2))
+}
+
+func (s *Shrinker) evaluator() Evaluator {
+	if s.Evaluator == nil {
+		return defaultEvaluator
+	}
+	return s.Evaluator
+}
+
+func (s *Shrinker) keep(original []int) func(vals []int) bool {
+	if s.Keep != nil {
+		return s.Keep
+	}
+	eval := s.evaluator()
+	if s.Threshold != 0 {
+		return func(vals []int) bool {
+			return len(vals) >= 2 && Intensity(eval.Cost(vals), len(vals)) >= s.Threshold
+		}
+	}
+	excess := ExcessGrowth(eval, original)
+	if excess < MinExcessGrowth {
+		return func([]int) bool {
+			return false
+		}
+	}
+	return func(vals []int) bool {
+		return len(vals) >= 4 && ExcessGrowth(eval, vals) >= excess
+	}
+}
+
+// Shrink returns the smallest array it can find that is still kept.  vals is not modified.
+func (s *Shrinker) Shrink(vals []int) []int {
+	keep := s.keep(vals)
+	ret := make([]int, len(vals))
+	copy(ret, vals)
+	ret = removeChunks(ret, keep)
+	ret = mergeValues(ret, keep)
+	return encoding.Ranks(ret)
+}
+
+// ShrinkChromosome shrinks c, which must come from ArraySortingFactory, scoring it with c's evaluator unless
+// Evaluator is set
+func (s *Shrinker) ShrinkChromosome(c genetic.Chromosome) genetic.Chromosome {
+	asArray := c.(*arraySortingIndividual)
+	withEval := *s
+	if withEval.Evaluator == nil {
+		withEval.Evaluator = asArray.evaluator()
+	}
+	return &arraySortingIndividual{
+		vals: withEval.Shrink(asArray.vals),
+		eval: asArray.eval,
+	}
+}
+
+// removeChunks is ddmin, trying only complements: split vals into n chunks and drop any chunk that isn't needed,
+// splitting finer when none can be dropped
+func removeChunks(vals []int, keep func([]int) bool) []int {
+	n := 2
+	for len(vals) > 2 {
+		chunk := (len(vals) + n - 1) / n
+		removed := false
+		for start := 0; start < len(vals); start += chunk {
+			end := min(start+chunk, len(vals))
+			candidate := make([]int, 0, len(vals)-(end-start))
+			candidate = append(candidate, vals[:start]...)
+			candidate = append(candidate, vals[end:]...)
+			if keep(candidate) {
+				vals = candidate
+				n = max(n-1, 2)
+				removed = true
+				break
+			}
+		}
+		if removed {
+			continue
+		}
+		if n >= len(vals) {
+			break
+		}
+		n = min(n*2, len(vals))
+	}
+	return vals
+}
+
+// mergeValues tries to give each value the value of the next smaller one, so fewer distinct values remain
+func mergeValues(vals []int, keep func([]int) bool) []int {
+	for merged := true; merged; {
+		merged = false
+		distinct := distinctValues(vals)
+		for i := len(distinct) - 1; i > 0; i-- {
+			candidate := make([]int, len(vals))
+			for j, v := range vals {
+				candidate[j] = v
+				if v == distinct[i] {
+					candidate[j] = distinct[i-1]
+				}
+			}
+			if keep(candidate) {
+				vals = candidate
+				merged = true
+			}
+		}
+	}
+	return vals
+}
+
+func distinctValues(vals []int) []int {
+	ret := make([]int, len(vals))
+	copy(ret, vals)
+	sort.Ints(ret)
+	n := 0
+	for i, v := range ret {
+		if i == 0 || v != ret[n-1] {
+			ret[n] = v
+			n++
+		}
+	}
+	return ret[:n]
+}
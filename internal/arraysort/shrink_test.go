@@ -0,0 +1,81 @@
+package arraysort
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+func TestShrinkerKeep(t *testing.T) {
+	// Keeps any array where a 7 comes before a 3
+	s := &Shrinker{
+		Keep: func(vals []int) bool {
+			for i, v := range vals {
+				if v != 7 {
+					continue
+				}
+				for _, w := range vals[i+1:] {
+					if w == 3 {
+						return true
+					}
+				}
+			}
+			return false
+		},
+	}
+	vals := []int{9, 1, 4, 7, 8, 8, 2, 6, 3, 5, 0}
+	if got := s.Shrink(vals); !reflect.DeepEqual(got, []int{1, 0}) {
+		t.Errorf("expected [1 0], got %v", got)
+	}
+	if vals[3] != 7 {
+		t.Errorf("Shrink modified its input")
+	}
+}
+
+func TestShrinkerThreshold(t *testing.T) {
+	s := &Shrinker{
+		Evaluator: &TargetEvaluator{
+			Target: insertionSortTarget,
+			Model:  mustLookupCostModel(""),
+		},
+		Threshold: 1,
+	}
+	vals := make([]int, 100)
+	for i := range vals {
+		vals[i] = len(vals) - i
+	}
+	got := s.Shrink(vals)
+	// Reversed insertion sort makes n(n-1)/2 comparisons, which is n·log2 n by n=7
+	if len(got) > 7 {
+		t.Errorf("expected at most 7 values, got %v", got)
+	}
+	if i := Intensity(s.Evaluator.Cost(got), len(got)); i < 1 {
+		t.Errorf("shrunk below the threshold: %v has intensity %f", got, i)
+	}
+}
+
+func TestShrinkerDefault(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	s := &Shrinker{}
+	// A random array isn't adversarial, so it shouldn't shrink to a handful of values that only look bad because
+	// small arrays are insertion sorted
+	for i := 0; i < 10; i++ {
+		if got := s.Shrink(r.Perm(200)); len(got) < 100 {
+			t.Fatalf("random array shrunk to %v", got)
+		}
+	}
+	killer := Adversary(mustLookupSortTarget("go1.5/sort.Sort"), 200)
+	s.Evaluator = &TargetEvaluator{Target: mustLookupSortTarget("go1.5/sort.Sort"), Model: mustLookupCostModel("")}
+	excess := ExcessGrowth(s.Evaluator, killer)
+	if excess < MinExcessGrowth {
+		t.Fatalf("killer has excess growth %f", excess)
+	}
+	// Removing values breaks this killer, but merging them keeps it quadratic
+	got := s.Shrink(killer)
+	if len(got) >= len(killer) && len(distinctValues(got)) >= len(distinctValues(killer)) {
+		t.Errorf("killer didn't shrink")
+	}
+	if e := ExcessGrowth(s.Evaluator, got); e < excess {
+		t.Errorf("shrunk to %v with excess growth %f, below %f", got, e, excess)
+	}
+}
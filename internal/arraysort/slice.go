@@ -0,0 +1,108 @@
+package arraysort
+
+import (
+	"hash/fnv"
+	"strings"
+
+	"github.com/cep21/geneticsort/genetic"
+)
+
+// sliceKind is what a family of sliceIndividual needs to know about its values
+type sliceKind[T any] struct {
+	random  func(r genetic.Rand) T
+	cost    func(vals []T) int
+	metrics func(vals []T) map[string]int
+	format  func(v T) string
+	// bytes feeds GenotypeHash
+	bytes func(v T) []byte
+}
+
+// sliceIndividual is an array chromosome of values other than ints
+type sliceIndividual[T any] struct {
+	vals    []T
+	fitness *int
+	kind    *sliceKind[T]
+}
+
+func newSliceIndividual[T any](kind *sliceKind[T], size int, r genetic.Rand) *sliceIndividual[T] {
+	c := &sliceIndividual[T]{
+		vals: make([]T, size),
+		kind: kind,
+	}
+	for i := range c.vals {
+		c.vals[i] = kind.random(r)
+	}
+	return c
+}
+
+func (c *sliceIndividual[T]) Fitness() int {
+	if c.fitness != nil {
+		return *c.fitness
+	}
+	fitness := c.kind.cost(c.vals)
+	c.fitness = &fitness
+	return fitness
+}
+
+func (c *sliceIndividual[T]) FitnessCached() bool {
+	return c.fitness != nil
+}
+
+func (c *sliceIndividual[T]) Metrics() map[string]int {
+	return c.kind.metrics(c.vals)
+}
+
+func (c *sliceIndividual[T]) Shell() genetic.Chromosome {
+	return &sliceIndividual[T]{
+		vals: make([]T, len(c.vals)),
+		kind: c.kind,
+	}
+}
+
+func (c *sliceIndividual[T]) Clone() genetic.Chromosome {
+	ret := c.Shell().(*sliceIndividual[T])
+	copy(ret.vals, c.vals)
+	return ret
+}
+
+func (c *sliceIndividual[T]) String() string {
+	var s strings.Builder
+	for i, v := range c.vals {
+		if i != 0 {
+			mustPrint(s.WriteString(","))
+		}
+		mustPrint(s.WriteString(c.kind.format(v)))
+	}
+	return s.String()
+}
+
+func (c *sliceIndividual[T]) GenotypeHash() uint64 {
+	h := fnv.New64a()
+	for _, v := range c.vals {
+		mustPrint(h.Write(c.kind.bytes(v)))
+		// Separates values, so "ab","c" and "a","bc" differ
+		mustPrint(h.Write([]byte{0}))
+	}
+	return h.Sum64()
+}
+
+func (c *sliceIndividual[T]) Swap(i, j int) {
+	c.vals[i], c.vals[j] = c.vals[j], c.vals[i]
+}
+
+func (c *sliceIndividual[T]) Copy(from genetic.Array, start int, end int, into int) {
+	copy(c.vals[into:], from.(*sliceIndividual[T]).vals[start:end])
+}
+
+func (c *sliceIndividual[T]) Randomize(idx int, r genetic.Rand) {
+	c.vals[idx] = c.kind.random(r)
+}
+
+func (c *sliceIndividual[T]) Len() int {
+	return len(c.vals)
+}
+
+var _ genetic.Array = &sliceIndividual[string]{}
+var _ genetic.CachedFitness = &sliceIndividual[string]{}
+var _ genetic.Genotype = &sliceIndividual[string]{}
+var _ genetic.Measurable = &sliceIndividual[string]{}
@@ -0,0 +1,207 @@
+package arraysort
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/cep21/geneticsort/genetic"
+)
+
+// StringCounter is the comparator handed to a StringSort.  Comparing strings costs more the longer their shared
+// prefix, so it counts the bytes each comparison looks at as well as the comparisons.
+type StringCounter struct {
+	Comparisons int
+	Bytes       int
+}
+
+// Compare is strings.Compare, looking at bytes like memcmp: up to and including the first that differs
+func (c *StringCounter) Compare(a, b string) int {
+	c.Comparisons++
+	n := min(len(a), len(b))
+	i := 0
+	for i < n && a[i] == b[i] {
+		i++
+	}
+	c.Bytes += min(i+1, n)
+	return strings.Compare(a, b)
+}
+
+func (c *StringCounter) Less(a, b string) bool {
+	return c.Compare(a, b) < 0
+}
+
+// StringSort sorts vals in place, comparing only through c
+type StringSort func(vals []string, c *StringCounter)
+
+type countingStrings struct {
+	vals []string
+	c    *StringCounter
+}
+
+func (c countingStrings) Len() int           { return len(c.vals) }
+func (c countingStrings) Less(i, j int) bool { return c.c.Less(c.vals[i], c.vals[j]) }
+func (c countingStrings) Swap(i, j int)      { c.vals[i], c.vals[j] = c.vals[j], c.vals[i] }
+
+const defaultStringSortTarget = "sort.Strings"
+
+var stringSortTargets = map[string]StringSort{
+	// sort.Strings is slices.Sort, which makes the same comparisons as slices.SortFunc.  See slices.Sort in
+	// builtinSortTargets.
+	"sort.Strings": func(vals []string, c *StringCounter) {
+		slices.SortFunc(vals, c.Compare)
+	},
+	"sort.Sort": func(vals []string, c *StringCounter) {
+		sort.Sort(countingStrings{vals: vals, c: c})
+	},
+	"sort.Slice": func(vals []string, c *StringCounter) {
+		sort.Slice(vals, func(i, j int) bool {
+			return c.Less(vals[i], vals[j])
+		})
+	},
+	"sort.SliceStable": func(vals []string, c *StringCounter) {
+		sort.SliceStable(vals, func(i, j int) bool {
+			return c.Less(vals[i], vals[j])
+		})
+	},
+}
+
+func RegisterStringSortTarget(name string, s StringSort) {
+	stringSortTargets[name] = s
+}
+
+func LookupStringSortTarget(name string) (StringSort, error) {
+	if name == "" {
+		name = defaultStringSortTarget
+	}
+	s, exists := stringSortTargets[name]
+	if !exists {
+		return nil, fmt.Errorf("unknown string sort target %q: valid targets are %v", name, StringSortTargetNames())
+	}
+	return s, nil
+}
+
+func StringSortTargetNames() []string {
+	ret := make([]string, 0, len(stringSortTargets))
+	for name := range stringSortTargets {
+		ret = append(ret, name)
+	}
+	sort.Strings(ret)
+	return ret
+}
+
+// StringSortingFactory spawns slices of strings that share long prefixes, so the bytes a sort compares matter as
+// much as how many comparisons it makes
+type StringSortingFactory struct {
+	IndividualSize int
+	// Target is the name of a registered StringSort.  Defaults to sort.Strings
+	Target string
+	// Cost is bytes or comparisons.  Defaults to bytes
+	Cost string
+	// Strings are a random prefix of a Stem of StemLength bytes followed by up to SuffixLength random bytes, all
+	// drawn from Alphabet.  Defaults are 32, 4 and "ab".
+	StemLength   int
+	SuffixLength int
+	Alphabet     string
+
+	kind *sliceKind[string]
+}
+
+var _ genetic.ChromosomeFactory = &StringSortingFactory{}
+
+func (f *StringSortingFactory) cost() string {
+	if f.Cost == "" {
+		return "bytes"
+	}
+	return f.Cost
+}
+
+func (f *StringSortingFactory) Family() string {
+	target := f.Target
+	if target == "" {
+		target = defaultStringSortTarget
+	}
+	return fmt.Sprintf("strings-sort-%d-%s-%s", f.IndividualSize, target, f.cost())
+}
+
+// CheckStringCost returns an error if target isn't a string sort target or cost isn't a string cost
+func CheckStringCost(target string, cost string) error {
+	if _, err := LookupStringSortTarget(target); err != nil {
+		return err
+	}
+	_, err := stringCost(cost)
+	return err
+}
+
+func stringCost(cost string) (func(c StringCounter) int, error) {
+	switch cost {
+	case "", "bytes":
+		return func(c StringCounter) int { return c.Bytes }, nil
+	case "comparisons":
+		return func(c StringCounter) int { return c.Comparisons }, nil
+	}
+	return nil, fmt.Errorf("unknown string cost %q: valid costs are bytes or comparisons", cost)
+}
+
+func (f *StringSortingFactory) sliceKind() *sliceKind[string] {
+	if f.kind != nil {
+		return f.kind
+	}
+	target, err := LookupStringSortTarget(f.Target)
+	if err != nil {
+		panic(err)
+	}
+	cost, err := stringCost(f.Cost)
+	if err != nil {
+		panic(err)
+	}
+	stemLength, suffixLength, alphabet := f.StemLength, f.SuffixLength, f.Alphabet
+	if stemLength <= 0 {
+		stemLength = 32
+	}
+	if suffixLength <= 0 {
+		suffixLength = 4
+	}
+	if alphabet == "" {
+		alphabet = "ab"
+	}
+	stem := strings.Repeat(alphabet[:1], stemLength)
+	counted := func(vals []string) StringCounter {
+		tmpVals := make([]string, len(vals))
+		copy(tmpVals, vals)
+		var c StringCounter
+		target(tmpVals, &c)
+		return c
+	}
+	f.kind = &sliceKind[string]{
+		random: func(r genetic.Rand) string {
+			var s strings.Builder
+			s.WriteString(stem[:r.Intn(len(stem)+1)])
+			for i := r.Intn(suffixLength + 1); i > 0; i-- {
+				s.WriteByte(alphabet[r.Intn(len(alphabet))])
+			}
+			return s.String()
+		},
+		cost: func(vals []string) int {
+			return cost(counted(vals))
+		},
+		metrics: func(vals []string) map[string]int {
+			c := counted(vals)
+			return map[string]int{
+				"bytes":       c.Bytes,
+				"comparisons": c.Comparisons,
+			}
+		},
+		format: strconv.Quote,
+		bytes: func(v string) []byte {
+			return []byte(v)
+		},
+	}
+	return f.kind
+}
+
+func (f *StringSortingFactory) Spawn(r genetic.Rand) genetic.Chromosome {
+	return newSliceIndividual(f.sliceKind(), f.IndividualSize, r)
+}
@@ -0,0 +1,82 @@
+package arraysort
+
+import (
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/cep21/geneticsort/genetic"
+)
+
+func TestStringCounterBytes(t *testing.T) {
+	for _, tc := range []struct {
+		a, b  string
+		bytes int
+	}{
+		{a: "", b: "a", bytes: 0},
+		{a: "ab", b: "ac", bytes: 2},
+		{a: "aaa", b: "aaa", bytes: 3},
+		{a: "aaab", b: "aa", bytes: 2},
+		{a: "b", b: "abc", bytes: 1},
+	} {
+		var c StringCounter
+		c.Compare(tc.a, tc.b)
+		if c.Bytes != tc.bytes || c.Comparisons != 1 {
+			t.Errorf("comparing %q and %q: got %d bytes, want %d", tc.a, tc.b, c.Bytes, tc.bytes)
+		}
+	}
+}
+
+func TestStringAndRecordTargetsSort(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for _, name := range StringSortTargetNames() {
+		c := (&StringSortingFactory{IndividualSize: 200, Target: name}).Spawn(r).(*sliceIndividual[string])
+		target, _ := LookupStringSortTarget(name)
+		target(c.vals, &StringCounter{})
+		if !sort.StringsAreSorted(c.vals) {
+			t.Errorf("%s did not sort", name)
+		}
+	}
+	for _, name := range RecordSortTargetNames() {
+		f := &RecordSortingFactory{IndividualSize: 200, Target: name}
+		c := f.Spawn(r).(*sliceIndividual[int])
+		if m := c.Metrics(); m["unstable"] != 0 || m["comparisons"] == 0 {
+			t.Errorf("%s was unstable or made no comparisons: %v", name, m)
+		}
+	}
+}
+
+func TestStringAndRecordOperators(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for _, f := range []genetic.ChromosomeFactory{
+		&StringSortingFactory{IndividualSize: 20},
+		&RecordSortingFactory{IndividualSize: 20},
+	} {
+		a, b := f.Spawn(r), f.Spawn(r)
+		child := (&genetic.OnePointCrossover{}).Reproduce([]genetic.Chromosome{a, b}, r)
+		mutated := (&genetic.IndexMutation{}).Mutate(child, r)
+		if mutated.Fitness() <= 0 || mutated.String() == "" {
+			t.Errorf("%s: expected a scored child, got %d for %s", f.Family(), mutated.Fitness(), mutated)
+		}
+	}
+}
+
+func TestCheckRecordCost(t *testing.T) {
+	for _, tc := range []struct {
+		target, cost string
+		ok           bool
+	}{
+		{target: "", cost: "", ok: true},
+		{target: "sort.Stable", cost: "swaps", ok: true},
+		{target: "", cost: "swaps", ok: false},
+		{target: "slices.SortStableFunc", cost: "comparisons+swaps", ok: false},
+		{target: "sort.SliceStable", cost: "allocs", ok: false},
+	} {
+		if err := CheckRecordCost(tc.target, tc.cost); (err == nil) != tc.ok {
+			t.Errorf("target %q cost %q: got %v", tc.target, tc.cost, err)
+		}
+	}
+	if err := CheckStringCost("", "swaps"); err == nil {
+		t.Error("expected strings to reject swaps")
+	}
+}
@@ -0,0 +1,188 @@
+package arraysort
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"sort"
+
+	"github.com/cep21/geneticsort/internal/arraysort/gosort/go118"
+	"github.com/cep21/geneticsort/internal/arraysort/gosort/go127"
+	"github.com/cep21/geneticsort/internal/arraysort/gosort/go15"
+)
+
+// Counter is the comparator handed to a SortTarget.  It counts how much work the sort does.
+type Counter struct {
+	Comparisons int
+	// Swaps only counts swaps made through Swap.  Sorts that move values any other way report 0.
+	Swaps int
+	// less, if set, replaces the natural order of ints
+	less func(a, b int) bool
+}
+
+func (c *Counter) Swap(vals []int, i, j int) {
+	c.Swaps++
+	vals[i], vals[j] = vals[j], vals[i]
+}
+
+func (c *Counter) Less(a, b int) bool {
+	c.Comparisons++
+	if c.less != nil {
+		return c.less(a, b)
+	}
+	return a < b
+}
+
+func (c *Counter) Compare(a, b int) int {
+	c.Comparisons++
+	if c.less != nil {
+		switch {
+		case c.less(a, b):
+			return -1
+		case c.less(b, a):
+			return 1
+		}
+		return 0
+	}
+	return cmp.Compare(a, b)
+}
+
+// SortTarget is a sort implementation under attack.  Sort must sort vals in place, comparing values only through c.
+type SortTarget interface {
+	Name() string
+	Sort(vals []int, c *Counter)
+}
+
+type funcSortTarget struct {
+	name        string
+	sort        func(vals []int, c *Counter)
+	countsSwaps bool
+}
+
+func (f *funcSortTarget) Name() string {
+	return f.name
+}
+
+func (f *funcSortTarget) Sort(vals []int, c *Counter) {
+	f.sort(vals, c)
+}
+
+func (f *funcSortTarget) CountsSwaps() bool {
+	return f.countsSwaps
+}
+
+// NewSortTarget makes a SortTarget out of a function, for in-house sorts
+func NewSortTarget(name string, sort func(vals []int, c *Counter)) SortTarget {
+	return &funcSortTarget{
+		name: name,
+		sort: sort,
+	}
+}
+
+// NewSwappingSortTarget is NewSortTarget for a sort that only moves values with c.Swap, so it can be scored by swaps
+func NewSwappingSortTarget(name string, sort func(vals []int, c *Counter)) SortTarget {
+	return &funcSortTarget{
+		name:        name,
+		sort:        sort,
+		countsSwaps: true,
+	}
+}
+
+// SwapCounter is implemented by targets that know whether Counter.Swaps means anything for them.  Targets that don't
+// implement it are assumed not to count swaps.
+type SwapCounter interface {
+	CountsSwaps() bool
+}
+
+func countsSwaps(t SortTarget) bool {
+	asSwapCounter, ok := t.(SwapCounter)
+	return ok && asSwapCounter.CountsSwaps()
+}
+
+// countingInts is a sort.Interface that compares through a Counter
+type countingInts struct {
+	vals []int
+	c    *Counter
+}
+
+func (c countingInts) Len() int           { return len(c.vals) }
+func (c countingInts) Less(i, j int) bool { return c.c.Less(c.vals[i], c.vals[j]) }
+func (c countingInts) Swap(i, j int)      { c.c.Swap(c.vals, i, j) }
+
+const defaultSortTarget = "sort.Slice"
+
+var sortTargets = builtinSortTargets()
+
+// RegisterSortTarget makes t available to LookupSortTarget and ArraySortingFactory by its name
+func RegisterSortTarget(t SortTarget) {
+	sortTargets[t.Name()] = t
+}
+
+func LookupSortTarget(name string) (SortTarget, error) {
+	if name == "" {
+		name = defaultSortTarget
+	}
+	t, exists := sortTargets[name]
+	if !exists {
+		return nil, fmt.Errorf("unknown sort target %q: valid targets are %v", name, SortTargetNames())
+	}
+	return t, nil
+}
+
+func mustLookupSortTarget(name string) SortTarget {
+	t, err := LookupSortTarget(name)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func SortTargetNames() []string {
+	ret := make([]string, 0, len(sortTargets))
+	for name := range sortTargets {
+		ret = append(ret, name)
+	}
+	sort.Strings(ret)
+	return ret
+}
+
+func builtinSortTargets() map[string]SortTarget {
+	ret := make(map[string]SortTarget)
+	register := func(t SortTarget) {
+		ret[t.Name()] = t
+	}
+	register(NewSortTarget("sort.Slice", func(vals []int, c *Counter) {
+		sort.Slice(vals, func(i, j int) bool {
+			return c.Less(vals[i], vals[j])
+		})
+	}))
+	register(NewSwappingSortTarget("sort.Sort", func(vals []int, c *Counter) {
+		sort.Sort(countingInts{vals: vals, c: c})
+	}))
+	register(NewSwappingSortTarget("sort.Stable", func(vals []int, c *Counter) {
+		sort.Stable(countingInts{vals: vals, c: c})
+	}))
+	// slices.Sort takes no comparator, so it can't be counted directly.  Its pdqsortOrdered is generated from the
+	// same template as the pdqsortCmpFunc behind slices.SortFunc, so it makes exactly the same comparisons.
+	register(NewSortTarget("slices.Sort", func(vals []int, c *Counter) {
+		slices.SortFunc(vals, c.Compare)
+	}))
+	register(NewSortTarget("slices.SortFunc", func(vals []int, c *Counter) {
+		slices.SortFunc(vals, c.Compare)
+	}))
+	// Frozen copies of sort.Sort from older releases, so a known bad input can be tracked across versions even as
+	// the standard library keeps changing
+	register(NewSwappingSortTarget("go1.5/sort.Sort", func(vals []int, c *Counter) {
+		go15.Sort(countingInts{vals: vals, c: c})
+	}))
+	register(NewSwappingSortTarget("go1.18/sort.Sort", func(vals []int, c *Counter) {
+		go118.Sort(countingInts{vals: vals, c: c})
+	}))
+	register(NewSwappingSortTarget("go1.27/sort.Sort", func(vals []int, c *Counter) {
+		go127.Sort(countingInts{vals: vals, c: c})
+	}))
+	register(NewSwappingSortTarget("go1.27/sort.Stable", func(vals []int, c *Counter) {
+		go127.Stable(countingInts{vals: vals, c: c})
+	}))
+	return ret
+}
@@ -0,0 +1,79 @@
+package arraysort
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+func TestSortTargetsSort(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	inputs := map[string][]int{
+		"empty": {},
+		"one":   {1},
+	}
+	for _, n := range []int{10, 13, 41, 500} {
+		random := make([]int, n)
+		sorted := make([]int, n)
+		reversed := make([]int, n)
+		dups := make([]int, n)
+		for i := 0; i < n; i++ {
+			random[i] = r.Intn(n)
+			sorted[i] = i
+			reversed[i] = n - i
+			dups[i] = r.Intn(3)
+		}
+		inputs[fmt.Sprintf("random-%d", n)] = random
+		inputs[fmt.Sprintf("sorted-%d", n)] = sorted
+		inputs[fmt.Sprintf("reversed-%d", n)] = reversed
+		inputs[fmt.Sprintf("dups-%d", n)] = dups
+	}
+	for _, name := range SortTargetNames() {
+		target := mustLookupSortTarget(name)
+		for inputName, input := range inputs {
+			vals := append([]int(nil), input...)
+			var c Counter
+			target.Sort(vals, &c)
+			if !sort.IntsAreSorted(vals) {
+				t.Errorf("%s did not sort %s", name, inputName)
+			}
+		}
+	}
+}
+
+func TestCheckCostModel(t *testing.T) {
+	swaps, err := LookupCostModel("swaps")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range SortTargetNames() {
+		target := mustLookupSortTarget(name)
+		err := CheckCostModel(target, swaps)
+		if countsSwaps(target) != (err == nil) {
+			t.Errorf("%s counts swaps: %v, but checking swaps returned %v", name, countsSwaps(target), err)
+		}
+		if err != nil {
+			continue
+		}
+		// Targets that claim to count swaps really do
+		var c Counter
+		target.Sort([]int{3, 1, 2, 5, 4}, &c)
+		if c.Swaps == 0 {
+			t.Errorf("%s counted no swaps", name)
+		}
+	}
+	if _, err := NewTargetEvaluator("sort.Slice", "comparisons+swaps"); err == nil {
+		t.Error("expected sort.Slice to be rejected for comparisons+swaps")
+	}
+	// Swaps that aren't counted aren't reported as 0 either
+	for target, wantSwaps := range map[string]bool{"sort.Slice": false, "sort.Sort": true} {
+		e, err := NewTargetEvaluator(target, "comparisons")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, hasSwaps := e.Metrics([]int{3, 1, 2})["swaps"]; hasSwaps != wantSwaps {
+			t.Errorf("%s reported swaps: %v", target, hasSwaps)
+		}
+	}
+}
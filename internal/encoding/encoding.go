@@ -0,0 +1,152 @@
+// Package encoding stores int arrays compactly.  Sorts only compare values, so arrays are stored as the ranks of their
+// values, which also makes every array with the same relative order encode, and hash, the same.
+package encoding
+
+import (
+	"bytes"
+	"compress/flate"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/cep21/geneticsort/genetic"
+)
+
+// Ints is implemented by chromosomes that are an array of ints
+type Ints interface {
+	genetic.Chromosome
+	// Ints must not be modified
+	Ints() []int
+}
+
+// Ranks replaces each value with its rank among the distinct values, which keeps every comparison the same.  For
+// distinct values it is the same as Simplify.
+func Ranks(vals []int) []int {
+	distinct := make([]int, len(vals))
+	copy(distinct, vals)
+	sort.Ints(distinct)
+	n := 0
+	for i, v := range distinct {
+		if i == 0 || v != distinct[n-1] {
+			distinct[n] = v
+			n++
+		}
+	}
+	distinct = distinct[:n]
+	ret := make([]int, len(vals))
+	for i, v := range vals {
+		ret[i] = sort.SearchInts(distinct, v)
+	}
+	return ret
+}
+
+// Format is the first byte of an encoding
+type Format byte
+
+const (
+	// Raw is a uvarint length followed by the difference between each rank and the one before it as a varint
+	Raw Format = iota
+	// Flate is Raw compressed with compress/flate
+	Flate
+)
+
+// Encode returns the ranks of vals in format.  Adversarial arrays are mostly runs and interleavings, whose deltas are
+// small and repeat, so Flate usually wins for large arrays.
+func Encode(vals []int, format Format) []byte {
+	raw := binary.AppendUvarint(nil, uint64(len(vals)))
+	prev := 0
+	for _, rank := range Ranks(vals) {
+		raw = binary.AppendVarint(raw, int64(rank-prev))
+		prev = rank
+	}
+	switch format {
+	case Raw:
+		return append([]byte{byte(Raw)}, raw...)
+	case Flate:
+		return append([]byte{byte(Flate)}, deflate(raw)...)
+	}
+	panic(fmt.Sprintf("unknown format %d", format))
+}
+
+// Decode returns the ranks Encode stored
+func Decode(b []byte) ([]int, error) {
+	if len(b) == 0 {
+		return nil, errors.New("empty encoding")
+	}
+	raw := b[1:]
+	switch Format(b[0]) {
+	case Raw:
+	case Flate:
+		var err error
+		if raw, err = inflate(raw); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unknown format %d", b[0])
+	}
+	r := bytes.NewReader(raw)
+	n, err := binary.ReadUvarint(r)
+	if err != nil {
+		return nil, err
+	}
+	// Every value takes at least a byte, which stops a corrupt length from allocating everything
+	if n > uint64(r.Len()) {
+		return nil, fmt.Errorf("length %d is longer than the %d bytes left", n, r.Len())
+	}
+	ret := make([]int, n)
+	prev := int64(0)
+	for i := range ret {
+		delta, err := binary.ReadVarint(r)
+		if err != nil {
+			return nil, err
+		}
+		prev += delta
+		ret[i] = int(prev)
+	}
+	if r.Len() != 0 {
+		return nil, fmt.Errorf("%d bytes left over", r.Len())
+	}
+	return ret, nil
+}
+
+// Canonical is the same for every array with the same relative order, and differs otherwise.  It is Raw, because
+// compressed bytes can change with the compressor.
+func Canonical(vals []int) []byte {
+	return Encode(vals, Raw)
+}
+
+// Hash is a sha256 of Canonical
+func Hash(vals []int) string {
+	h := sha256.Sum256(Canonical(vals))
+	return base64.StdEncoding.EncodeToString(h[:])
+}
+
+func deflate(raw []byte) []byte {
+	var buf bytes.Buffer
+	// Only errors for an invalid level or a failing writer, and bytes.Buffer never fails
+	w, err := flate.NewWriter(&buf, flate.BestCompression)
+	mustNil(err)
+	mustWrite(w.Write(raw))
+	mustNil(w.Close())
+	return buf.Bytes()
+}
+
+func inflate(compressed []byte) ([]byte, error) {
+	r := flate.NewReader(bytes.NewReader(compressed))
+	defer r.Close()
+	return io.ReadAll(r)
+}
+
+func mustNil(err error) {
+	if err != nil {
+		panic(err)
+	}
+}
+
+func mustWrite(_ int, err error) {
+	mustNil(err)
+}
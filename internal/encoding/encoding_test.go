@@ -0,0 +1,59 @@
+package encoding
+
+import (
+	"fmt"
+	"math/rand"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestRoundTrip(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for _, n := range []int{0, 1, 2, 100, 5000} {
+		vals := make([]int, n)
+		for i := range vals {
+			vals[i] = r.Intn(n/2+1) - n/4
+		}
+		for _, format := range []Format{Raw, Flate} {
+			got, err := Decode(Encode(vals, format))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(got, Ranks(vals)) {
+				t.Errorf("format %d n=%d decoded %v", format, n, got)
+			}
+		}
+	}
+}
+
+func TestCanonical(t *testing.T) {
+	if Hash([]int{5, -3, 5, 100}) != Hash([]int{1, 0, 1, 2}) {
+		t.Error("order equivalent arrays hash differently")
+	}
+	if Hash([]int{1, 0, 1, 2}) == Hash([]int{1, 0, 2, 2}) {
+		t.Error("different orders hash the same")
+	}
+}
+
+func TestCompact(t *testing.T) {
+	// An organ pipe, like the arrays evolution finds
+	vals := make([]int, 10000)
+	for i := range vals {
+		vals[i] = min(i, len(vals)-i)
+	}
+	text := len(strings.Trim(fmt.Sprint(vals), "[]"))
+	raw, compressed := len(Encode(vals, Raw)), len(Encode(vals, Flate))
+	if raw*2 > text || compressed*10 > raw {
+		t.Errorf("text=%d raw=%d flate=%d", text, raw, compressed)
+	}
+}
+
+func TestDecodeErrors(t *testing.T) {
+	valid := Encode([]int{3, 1, 2}, Raw)
+	for _, b := range [][]byte{nil, {9}, valid[:len(valid)-1], append(valid, 0), {byte(Raw), 200, 1}, {byte(Flate), 1, 2}} {
+		if _, err := Decode(b); err == nil {
+			t.Errorf("decoded %v", b)
+		}
+	}
+}
@@ -6,6 +6,8 @@ import (
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/cep21/geneticsort/internal/encoding"
 	"github.com/cep21/geneticsort/internal/record"
 )
 
@@ -17,40 +19,72 @@ type Recorder struct {
 var _ record.Recorder = &Recorder{}
 
 func (d *Recorder) Record(ctx context.Context, r record.Record) error {
-	_, err := d.Client.PutItemWithContext(ctx, &dynamodb.PutItemInput{
+	item, err := recordItem(r)
+	if err != nil {
+		return err
+	}
+	_, err = d.Client.PutItemWithContext(ctx, &dynamodb.PutItemInput{
 		TableName: &d.TableName,
-		Item: map[string]*dynamodb.AttributeValue{
-			"key": {
-				S: aws.String(r.Hash()),
-			},
-			"best": {
-				S: aws.String(r.BestCandidate.String()),
-			},
-			"fitness": {
-				N: aws.String(strconv.Itoa(r.BestCandidate.Fitness())),
-			},
-			"parent_select": {
-				S: aws.String(r.Algorithm.ParentSelector.String()),
-			},
-			"family": {
-				S: aws.String(r.Algorithm.Factory.Family()),
-			},
-			"mutator": {
-				S: aws.String(r.Algorithm.Mutator.String()),
-			},
-			"terminator": {
-				S: aws.String(r.Algorithm.Terminator.String()),
-			},
-			"crossover": {
-				S: aws.String(r.Algorithm.Crossover.String()),
-			},
-			"survivor_selection": {
-				S: aws.String(r.Algorithm.SurvivorSelection.String()),
-			},
-			"population_size": {
-				N: aws.String(strconv.Itoa(r.Algorithm.PopulationSize)),
-			},
-		},
+		Item:      item,
 	})
 	return err
 }
+
+func recordItem(r record.Record) (map[string]*dynamodb.AttributeValue, error) {
+	item := map[string]*dynamodb.AttributeValue{
+		"key": {
+			S: aws.String(r.Hash()),
+		},
+		"fitness": {
+			N: aws.String(strconv.Itoa(r.BestCandidate.Fitness())),
+		},
+		"parent_select": {
+			S: aws.String(r.Algorithm.ParentSelector.String()),
+		},
+		"family": {
+			S: aws.String(r.Algorithm.Factory.Family()),
+		},
+		"mutator": {
+			S: aws.String(r.Algorithm.Mutator.String()),
+		},
+		"terminator": {
+			S: aws.String(r.Algorithm.Terminator.String()),
+		},
+		"crossover": {
+			S: aws.String(r.Algorithm.Crossover.String()),
+		},
+		"survivor_selection": {
+			S: aws.String(r.Algorithm.SurvivorSelection.String()),
+		},
+		"population_size": {
+			N: aws.String(strconv.Itoa(r.Algorithm.PopulationSize)),
+		},
+	}
+	// Int arrays are stored only as their flate compressed ranks, which is a fraction of the size of their String
+	if asInts, ok := r.BestCandidate.(encoding.Ints); ok {
+		item["best_ranks"] = &dynamodb.AttributeValue{
+			B: encoding.Encode(asInts.Ints(), encoding.Flate),
+		}
+	} else {
+		item["best"] = &dynamodb.AttributeValue{
+			S: aws.String(r.BestCandidate.String()),
+		}
+	}
+	if candidateMetrics := r.CandidateMetrics(); candidateMetrics != nil {
+		metrics := make(map[string]*dynamodb.AttributeValue)
+		for name, value := range candidateMetrics {
+			metrics[name] = &dynamodb.AttributeValue{
+				N: aws.String(strconv.Itoa(value)),
+			}
+		}
+		item["metrics"] = &dynamodb.AttributeValue{
+			M: metrics,
+		}
+	}
+	manifest, err := dynamodbattribute.Marshal(r.Manifest)
+	if err != nil {
+		return nil, err
+	}
+	item["manifest"] = manifest
+	return item, nil
+}
@@ -0,0 +1,57 @@
+package dynamorecord
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/cep21/geneticsort/genetic"
+	"github.com/cep21/geneticsort/internal/arraysort"
+	"github.com/cep21/geneticsort/internal/record"
+)
+
+// itemSize is roughly how DynamoDB sizes an item: the length of each attribute name and value
+func itemSize(item map[string]*dynamodb.AttributeValue) int {
+	size := 0
+	for name, v := range item {
+		size += len(name)
+		switch {
+		case v.S != nil:
+			size += len(*v.S)
+		case v.N != nil:
+			size += len(*v.N)
+		case v.B != nil:
+			size += len(v.B)
+		case v.M != nil:
+			size += itemSize(v.M)
+		}
+	}
+	return size
+}
+
+func TestRecordItem(t *testing.T) {
+	factory := &arraysort.ArraySortingFactory{IndividualSize: 10000}
+	best := factory.Spawn(rand.New(rand.NewSource(1)))
+	item, err := recordItem(record.Record{
+		Algorithm: genetic.Algorithm{
+			ParentSelector:    genetic.TournamentParentSelector{K: 3},
+			Factory:           factory,
+			Terminator:        &genetic.CountingTermination{Limit: 1},
+			Crossover:         &genetic.OnePointCrossover{},
+			SurvivorSelection: &genetic.PlusSurvivorSelection{},
+			Mutator:           &genetic.IndexMutation{},
+			PopulationSize:    10,
+		},
+		BestCandidate: best,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if item["best"] != nil || item["best_ranks"] == nil {
+		t.Fatal("int arrays should only be stored as best_ranks")
+	}
+	// The whole item is smaller than the array's String alone
+	if size, stringSize := itemSize(item), len(best.String()); size >= stringSize {
+		t.Errorf("item is %d bytes, but the array's String is only %d", size, stringSize)
+	}
+}
@@ -0,0 +1,153 @@
+package record
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Sink is a named Recorder, for reporting which ones failed
+type Sink struct {
+	Name     string
+	Recorder Recorder
+}
+
+// FanOut records to every Sink at once, retrying each with exponential backoff.  A record that a sink still fails to
+// take goes to Spool, so it can be replayed once the sink is back.
+type FanOut struct {
+	Sinks []Sink
+	// Retries is how many times to retry a failing sink, so 0 tries each sink once.  Negative uses DefaultRetries.
+	Retries int
+	// Backoff is how long to wait before the first retry, doubled for each one after, up to MaxBackoff.  Defaults
+	// are 1s and 30s.
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+	// Spool, if set, records whatever a sink failed to
+	Spool Recorder
+	Log   *log.Logger
+}
+
+var _ Recorder = &FanOut{}
+
+// DefaultRetries is how many times FanOut retries with a negative Retries
+const DefaultRetries = 4
+
+// FanOutError is every sink's outcome for a record that at least one sink failed to take
+type FanOutError struct {
+	Succeeded []string
+	Failed    map[string]error
+	// Spooled is whether Spool took the record.  If not, SpoolErr is why, or nil if there is no Spool.
+	Spooled  bool
+	SpoolErr error
+}
+
+func (e *FanOutError) Error() string {
+	failed := make([]string, 0, len(e.Failed))
+	for name, err := range e.Failed {
+		failed = append(failed, fmt.Sprintf("%s: %v", name, err))
+	}
+	sort.Strings(failed)
+	ret := fmt.Sprintf("recorded to [%s], failed [%s]", strings.Join(e.Succeeded, " "), strings.Join(failed, "; "))
+	switch {
+	case e.Spooled:
+		return ret + ", spooled"
+	case e.SpoolErr != nil:
+		return ret + ", unable to spool: " + e.SpoolErr.Error()
+	}
+	return ret
+}
+
+func (e *FanOutError) Unwrap() []error {
+	ret := make([]error, 0, len(e.Failed)+1)
+	for _, err := range e.Failed {
+		ret = append(ret, err)
+	}
+	if e.SpoolErr != nil {
+		ret = append(ret, e.SpoolErr)
+	}
+	return ret
+}
+
+func (f *FanOut) retries() int {
+	if f.Retries < 0 {
+		return DefaultRetries
+	}
+	return f.Retries
+}
+
+func (f *FanOut) backoff(retry int) time.Duration {
+	backoff, maxBackoff := f.Backoff, f.MaxBackoff
+	if backoff <= 0 {
+		backoff = time.Second
+	}
+	if maxBackoff <= 0 {
+		maxBackoff = 30 * time.Second
+	}
+	for i := 0; i < retry && backoff < maxBackoff; i++ {
+		backoff *= 2
+	}
+	return min(backoff, maxBackoff)
+}
+
+// Record returns a *FanOutError if any sink failed.  Metrics are measured once, before any sink is called, so sinks
+// running at the same time don't disturb each other's measurements and every sink records the same ones.
+func (f *FanOut) Record(ctx context.Context, r Record) error {
+	r.Metrics = r.CandidateMetrics()
+	errs := make([]error, len(f.Sinks))
+	var wg sync.WaitGroup
+	for i, sink := range f.Sinks {
+		wg.Add(1)
+		go func(i int, sink Sink) {
+			defer wg.Done()
+			errs[i] = f.recordWithRetries(ctx, sink, r)
+		}(i, sink)
+	}
+	wg.Wait()
+	ret := &FanOutError{
+		Failed: make(map[string]error),
+	}
+	for i, sink := range f.Sinks {
+		if errs[i] == nil {
+			ret.Succeeded = append(ret.Succeeded, sink.Name)
+		} else {
+			ret.Failed[sink.Name] = errs[i]
+		}
+	}
+	if len(ret.Failed) == 0 {
+		return nil
+	}
+	if f.Spool != nil {
+		// The sinks may have failed because ctx ran out, which shouldn't stop the record being saved locally
+		ret.SpoolErr = f.Spool.Record(context.WithoutCancel(ctx), r)
+		ret.Spooled = ret.SpoolErr == nil
+	}
+	return ret
+}
+
+func (f *FanOut) recordWithRetries(ctx context.Context, sink Sink, r Record) error {
+	var err error
+	for retry := 0; ; retry++ {
+		if err = sink.Recorder.Record(ctx, r); err == nil {
+			return nil
+		}
+		if retry == f.retries() {
+			return err
+		}
+		backoff := f.backoff(retry)
+		if f.Log != nil {
+			f.Log.Printf("unable to record to %s, retrying in %s: %v", sink.Name, backoff, err)
+		}
+		t := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return errors.Join(err, ctx.Err())
+		case <-t.C:
+		}
+	}
+}
@@ -0,0 +1,129 @@
+package record
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/cep21/geneticsort/genetic"
+)
+
+// flakyRecorder fails its first Failures calls
+type flakyRecorder struct {
+	Failures int32
+	calls    atomic.Int32
+}
+
+var errFlaky = errors.New("throttled")
+
+func (f *flakyRecorder) Record(ctx context.Context, r Record) error {
+	if f.calls.Add(1) <= f.Failures {
+		return errFlaky
+	}
+	return nil
+}
+
+func TestFanOut(t *testing.T) {
+	flaky, down, spool := &flakyRecorder{Failures: 2}, &flakyRecorder{Failures: 100}, &flakyRecorder{}
+	f := &FanOut{
+		Sinks: []Sink{
+			{Name: "flaky", Recorder: flaky},
+			{Name: "down", Recorder: down},
+		},
+		Retries: 3,
+		Backoff: time.Millisecond,
+		Spool:   spool,
+	}
+	err := f.Record(context.Background(), Record{})
+	var asFanOut *FanOutError
+	if !errors.As(err, &asFanOut) {
+		t.Fatalf("expected a FanOutError, got %v", err)
+	}
+	if len(asFanOut.Succeeded) != 1 || asFanOut.Succeeded[0] != "flaky" || asFanOut.Failed["down"] == nil || !asFanOut.Spooled {
+		t.Errorf("unexpected outcome %+v", asFanOut)
+	}
+	if !errors.Is(err, errFlaky) || !strings.Contains(err.Error(), "down: throttled") {
+		t.Errorf("unexpected error %v", err)
+	}
+	if flaky.calls.Load() != 3 || down.calls.Load() != 4 || spool.calls.Load() != 1 {
+		t.Errorf("calls: flaky=%d down=%d spool=%d", flaky.calls.Load(), down.calls.Load(), spool.calls.Load())
+	}
+
+	flaky.calls.Store(0)
+	f.Sinks = f.Sinks[:1]
+	if err := f.Record(context.Background(), Record{}); err != nil {
+		t.Errorf("expected retries to succeed, got %v", err)
+	}
+	if spool.calls.Load() != 1 {
+		t.Error("spooled a record every sink took")
+	}
+}
+
+// measuredCandidate counts how often it is measured
+type measuredCandidate struct {
+	genetic.Chromosome
+	measured atomic.Int32
+}
+
+func (m *measuredCandidate) Metrics() map[string]int {
+	return map[string]int{"measured": int(m.measured.Add(1))}
+}
+
+// metricsRecorder fails its first Failures calls, after reading the record's metrics
+type metricsRecorder struct {
+	flakyRecorder
+	seen atomic.Int32
+}
+
+func (m *metricsRecorder) Record(ctx context.Context, r Record) error {
+	m.seen.Store(int32(r.CandidateMetrics()["measured"]))
+	return m.flakyRecorder.Record(ctx, r)
+}
+
+func TestFanOutMeasuresOnce(t *testing.T) {
+	candidate := &measuredCandidate{}
+	first, second := &metricsRecorder{flakyRecorder: flakyRecorder{Failures: 2}}, &metricsRecorder{}
+	f := &FanOut{
+		Sinks: []Sink{
+			{Name: "first", Recorder: first},
+			{Name: "second", Recorder: second},
+		},
+		Retries: 3,
+		Backoff: time.Millisecond,
+	}
+	if err := f.Record(context.Background(), Record{BestCandidate: candidate}); err != nil {
+		t.Fatal(err)
+	}
+	if candidate.measured.Load() != 1 || first.seen.Load() != 1 || second.seen.Load() != 1 {
+		t.Errorf("measured %d times, sinks saw measurement %d and %d", candidate.measured.Load(), first.seen.Load(), second.seen.Load())
+	}
+}
+
+func TestFanOutRetries(t *testing.T) {
+	for retries, wantCalls := range map[int]int32{0: 1, 2: 3, -1: DefaultRetries + 1} {
+		down := &flakyRecorder{Failures: 100}
+		f := &FanOut{
+			Sinks:   []Sink{{Name: "down", Recorder: down}},
+			Retries: retries,
+			Backoff: time.Millisecond,
+		}
+		if err := f.Record(context.Background(), Record{}); err == nil {
+			t.Errorf("retries=%d: expected an error", retries)
+		}
+		if down.calls.Load() != wantCalls {
+			t.Errorf("retries=%d: called %d times, not %d", retries, down.calls.Load(), wantCalls)
+		}
+	}
+}
+
+func TestFanOutBackoff(t *testing.T) {
+	f := &FanOut{Backoff: time.Second, MaxBackoff: 5 * time.Second}
+	for retry, want := range []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second} {
+		if got := f.backoff(retry); got != want {
+			t.Errorf("retry %d backed off %s, not %s", retry, got, want)
+		}
+	}
+}
@@ -0,0 +1,228 @@
+// Package filerecord keeps records on the local filesystem, so runs and analysis work without AWS.  Records are
+// appended to Dir/records.jsonl, one JSON line each, and candidates too large to inline are stored once each under
+// Dir/candidates, named by the sha256 of their contents.
+package filerecord
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/cep21/geneticsort/genetic"
+	"github.com/cep21/geneticsort/internal/encoding"
+	"github.com/cep21/geneticsort/internal/record"
+)
+
+const (
+	recordsFile   = "records.jsonl"
+	candidatesDir = "candidates"
+	// defaultInlineLimit is a bit more than a 100 element array of random ints
+	defaultInlineLimit = 2048
+)
+
+// Entry is one line of records.jsonl
+type Entry struct {
+	Key     string `json:"key"`
+	Family  string `json:"family"`
+	Fitness int    `json:"fitness"`
+	// Best is the candidate's String, if it is small.  Otherwise BestFile is where it is, relative to Dir.
+	Best              string           `json:"best,omitempty"`
+	BestFile          string           `json:"best_file,omitempty"`
+	Metrics           map[string]int   `json:"metrics,omitempty"`
+	ParentSelect      string           `json:"parent_select"`
+	Mutator           string           `json:"mutator"`
+	Terminator        string           `json:"terminator"`
+	Crossover         string           `json:"crossover"`
+	SurvivorSelection string           `json:"survivor_selection"`
+	PopulationSize    int              `json:"population_size"`
+	Manifest          genetic.Manifest `json:"manifest"`
+}
+
+type Recorder struct {
+	Dir string
+	// InlineLimit is the longest candidate String kept in records.jsonl.  Defaults to 2048
+	InlineLimit int
+
+	mu sync.Mutex
+}
+
+var _ record.Recorder = &Recorder{}
+
+func (f *Recorder) inlineLimit() int {
+	if f.InlineLimit <= 0 {
+		return defaultInlineLimit
+	}
+	return f.InlineLimit
+}
+
+func (f *Recorder) Record(ctx context.Context, r record.Record) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	e := Entry{
+		Key:               r.Hash(),
+		Family:            r.Algorithm.Factory.Family(),
+		Fitness:           r.BestCandidate.Fitness(),
+		ParentSelect:      r.Algorithm.ParentSelector.String(),
+		Mutator:           r.Algorithm.Mutator.String(),
+		Terminator:        r.Algorithm.Terminator.String(),
+		Crossover:         r.Algorithm.Crossover.String(),
+		SurvivorSelection: r.Algorithm.SurvivorSelection.String(),
+		PopulationSize:    r.Algorithm.PopulationSize,
+		Manifest:          r.Manifest,
+		Metrics:           r.CandidateMetrics(),
+	}
+	best := r.BestCandidate.String()
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := os.MkdirAll(f.Dir, 0755); err != nil {
+		return err
+	}
+	if len(best) <= f.inlineLimit() {
+		e.Best = best
+	} else {
+		// Int arrays are stored like dynamorecord stores best_ranks
+		contents, ext := []byte(best), ".txt"
+		if asInts, ok := r.BestCandidate.(encoding.Ints); ok {
+			contents, ext = encoding.Encode(asInts.Ints(), encoding.Flate), ".bin"
+		}
+		if err := os.MkdirAll(filepath.Join(f.Dir, candidatesDir), 0755); err != nil {
+			return err
+		}
+		sum := sha256.Sum256(contents)
+		e.BestFile = filepath.Join(candidatesDir, hex.EncodeToString(sum[:])+ext)
+		if err := writeOnce(filepath.Join(f.Dir, e.BestFile), contents); err != nil {
+			return err
+		}
+	}
+	line, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	out, err := os.OpenFile(filepath.Join(f.Dir, recordsFile), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	// One write per line, so other processes appending to the same file don't interleave with it
+	if _, err := out.Write(append(line, '\n')); err != nil {
+		_ = out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// writeOnce writes a content addressed file, unless it is already there
+func writeOnce(path string, contents []byte) error {
+	if _, err := os.Stat(path); err == nil {
+		return nil
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, contents, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// List returns every record in the order they were recorded
+func (f *Recorder) List() ([]Entry, error) {
+	in, err := os.Open(filepath.Join(f.Dir, recordsFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = in.Close()
+	}()
+	var ret []Entry
+	scanner := bufio.NewScanner(in)
+	scanner.Buffer(nil, 1<<26)
+	for line := 1; scanner.Scan(); line++ {
+		var e Entry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			return nil, fmt.Errorf("%s line %d: %w", recordsFile, line, err)
+		}
+		ret = append(ret, e)
+	}
+	return ret, scanner.Err()
+}
+
+// Family returns the records of one family, in the order they were recorded
+func (f *Recorder) Family(family string) ([]Entry, error) {
+	entries, err := f.List()
+	if err != nil {
+		return nil, err
+	}
+	ret := entries[:0]
+	for _, e := range entries {
+		if e.Family == family {
+			ret = append(ret, e)
+		}
+	}
+	return ret, nil
+}
+
+// Top returns the k fittest distinct candidates of family, or of every family if family is empty, fittest first.
+// k <= 0 returns every distinct candidate.
+func (f *Recorder) Top(family string, k int) ([]Entry, error) {
+	var entries []Entry
+	var err error
+	if family == "" {
+		entries, err = f.List()
+	} else {
+		entries, err = f.Family(family)
+	}
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Fitness > entries[j].Fitness
+	})
+	seen := make(map[string]struct{})
+	var ret []Entry
+	for _, e := range entries {
+		if k > 0 && len(ret) == k {
+			break
+		}
+		if _, exists := seen[e.Key]; exists {
+			continue
+		}
+		seen[e.Key] = struct{}{}
+		ret = append(ret, e)
+	}
+	return ret, nil
+}
+
+// Candidate is e's best candidate, as its String.  Int arrays stored in files come back as their ranks.
+func (f *Recorder) Candidate(e Entry) (string, error) {
+	if e.BestFile == "" {
+		return e.Best, nil
+	}
+	contents, err := os.ReadFile(filepath.Join(f.Dir, e.BestFile))
+	if err != nil {
+		return "", err
+	}
+	if filepath.Ext(e.BestFile) != ".bin" {
+		return string(contents), nil
+	}
+	vals, err := encoding.Decode(contents)
+	if err != nil {
+		return "", err
+	}
+	s := make([]string, len(vals))
+	for i, v := range vals {
+		s[i] = strconv.Itoa(v)
+	}
+	return strings.Join(s, ","), nil
+}
@@ -0,0 +1,97 @@
+package filerecord
+
+import (
+	"context"
+	"math/rand"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/cep21/geneticsort/genetic"
+	"github.com/cep21/geneticsort/internal/arraysort"
+	"github.com/cep21/geneticsort/internal/encoding"
+	"github.com/cep21/geneticsort/internal/record"
+)
+
+func testRecord(size int, seed int64) record.Record {
+	factory := &arraysort.ArraySortingFactory{IndividualSize: size}
+	return record.Record{
+		Algorithm: genetic.Algorithm{
+			ParentSelector:    genetic.TournamentParentSelector{K: 3},
+			Factory:           factory,
+			Terminator:        &genetic.CountingTermination{Limit: 1},
+			Crossover:         &genetic.OnePointCrossover{},
+			SurvivorSelection: &genetic.PlusSurvivorSelection{},
+			Mutator:           &genetic.IndexMutation{},
+			PopulationSize:    10,
+		},
+		BestCandidate: factory.Spawn(rand.New(rand.NewSource(seed))),
+		Manifest:      genetic.Manifest{Seed: seed},
+	}
+}
+
+func TestRecorder(t *testing.T) {
+	f := &Recorder{Dir: t.TempDir()}
+	records := []record.Record{testRecord(10, 1), testRecord(500, 2), testRecord(10, 3), testRecord(10, 1)}
+	for _, r := range records {
+		if err := f.Record(context.Background(), r); err != nil {
+			t.Fatal(err)
+		}
+	}
+	all, err := f.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != len(records) {
+		t.Fatalf("listed %d records, not %d", len(all), len(records))
+	}
+	for i, e := range all {
+		if e.Key != records[i].Hash() || e.Manifest.Seed != records[i].Manifest.Seed {
+			t.Errorf("record %d is %+v", i, e)
+		}
+		candidate, err := f.Candidate(e)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// Stored candidates come back as ranks, which hash the same
+		if encoding.Hash(parseInts(t, candidate)) != encoding.Hash(records[i].BestCandidate.(encoding.Ints).Ints()) {
+			t.Errorf("record %d candidate changed", i)
+		}
+	}
+	if all[0].BestFile != "" || all[1].BestFile == "" {
+		t.Errorf("expected only the large candidate in a file: %q %q", all[0].BestFile, all[1].BestFile)
+	}
+
+	small, err := f.Family(records[0].Algorithm.Factory.Family())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(small) != 3 {
+		t.Errorf("family has %d records, not 3", len(small))
+	}
+	top, err := f.Top(records[0].Algorithm.Factory.Family(), 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The repeated record is only returned once
+	if len(top) != 2 || top[0].Fitness < top[1].Fitness {
+		t.Errorf("unexpected top records %+v", top)
+	}
+	for _, k := range []int{0, -1} {
+		if all, err := f.Top("", k); err != nil || len(all) != 3 {
+			t.Errorf("top %d returned %d records, %v", k, len(all), err)
+		}
+	}
+}
+
+func parseInts(t *testing.T, s string) []int {
+	var ret []int
+	for _, field := range strings.Split(s, ",") {
+		v, err := strconv.Atoi(field)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ret = append(ret, v)
+	}
+	return ret
+}
@@ -7,11 +7,29 @@ import (
 	"io"
 
 	"github.com/cep21/geneticsort/genetic"
+	"github.com/cep21/geneticsort/internal/encoding"
 )
 
 type Record struct {
 	Algorithm     genetic.Algorithm
 	BestCandidate genetic.Chromosome
+	// Manifest describes the run that found BestCandidate, usually Algorithm.Manifest after Run
+	Manifest genetic.Manifest
+	// Metrics are BestCandidate's, if it is Measurable.  Set them so they are measured only once, since measuring
+	// wall time or allocations again gives different numbers.
+	Metrics map[string]int
+}
+
+// CandidateMetrics is Metrics, or BestCandidate's metrics measured now if they aren't set, or nil if it isn't
+// Measurable
+func (r *Record) CandidateMetrics() map[string]int {
+	if r.Metrics != nil {
+		return r.Metrics
+	}
+	if asMeasurable, ok := r.BestCandidate.(genetic.Measurable); ok {
+		return asMeasurable.Metrics()
+	}
+	return nil
 }
 
 func mustWrite(_ int, err error) {
@@ -20,7 +38,13 @@ func mustWrite(_ int, err error) {
 	}
 }
 
+// Hash identifies the best candidate within its family.  Int arrays hash their canonical encoding, so arrays with the
+// same relative order hash the same.  Those hashes are marked v2, so they never collide with keys from before, which
+// hashed the String.
 func (r *Record) Hash() string {
+	if asInts, ok := r.BestCandidate.(encoding.Ints); ok {
+		return r.Algorithm.Factory.Family() + ":v2:" + encoding.Hash(asInts.Ints())
+	}
 	h := sha256.New()
 	mustWrite(io.WriteString(h, r.BestCandidate.String()))
 	return r.Algorithm.Factory.Family() + ":" + base64.StdEncoding.EncodeToString(h.Sum(nil))
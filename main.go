@@ -2,18 +2,23 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"log"
-	"math/rand"
 	"os"
+	"reflect"
 	"runtime"
 	"strconv"
+	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/dynamodb"
 	"github.com/cep21/geneticsort/internal/record"
 	"github.com/cep21/geneticsort/internal/record/dynamorecord"
+	"github.com/cep21/geneticsort/internal/record/filerecord"
 
 	"github.com/cep21/geneticsort/genetic"
 	"github.com/cep21/geneticsort/internal/arraysort"
@@ -25,9 +30,38 @@ type runConfig struct {
 	Duration         time.Duration
 	MutationRation   int
 	PopulationSize   int
+	OffspringSize    int
+	Survivors        string
+	MaxAge           int
 	Seed             int64
+	ArrayIndex       int64
 	TerminationStall int
+	TargetFitness    int
+	EvaluationBudget int64
+	Termination      string
 	DynamoDBTable    string
+	ResultsDir       string
+	SpoolDir         string
+	RecordRetries    int
+	RecordBackoff    time.Duration
+	SortTarget       string
+	CostModel        string
+	NumGoroutine     int
+	ReferenceTarget  string
+	Differential     string
+	ExternalSort     []string
+	ExternalTimeout  time.Duration
+	ExternalFailure  int
+	SeedFraction     float64
+	SeedPatterns     []string
+	Shrink           bool
+	ShrinkThreshold  float64
+	Chromosome       string
+	GenerativeOps    int
+	GenerativeSizes  []int
+	VerifySizes      []int
+	FloatOracle      bool
+	SpecialRate      float64
 }
 
 func load() runConfig {
@@ -36,13 +70,109 @@ func load() runConfig {
 	ret.KTournament = mustOsInt("K_TOURNAMENT", 3)
 	ret.MutationRation = mustOsInt("MUTATION_RATION", 30)
 	ret.PopulationSize = mustOsInt("POPULATION_SIZE", 1000)
+	ret.OffspringSize = mustOsInt("OFFSPRING_SIZE", ret.PopulationSize)
+	if ret.OffspringSize < 0 {
+		panic(fmt.Sprintf("OFFSPRING_SIZE must not be negative, not %d", ret.OffspringSize))
+	}
+	ret.Survivors = os.Getenv("SURVIVOR_SELECTION")
+	ret.MaxAge = mustOsInt("MAX_AGE", 10)
 	ret.TerminationStall = mustOsInt("TERMINATE_ON_STALL", 50)
+	ret.TargetFitness = mustOsInt("TARGET_FITNESS", 0)
+	ret.EvaluationBudget = mustOsInt64("EVALUATION_BUDGET", 0)
+	// Overrides RUN_TIME, TERMINATE_ON_STALL, TARGET_FITNESS and EVALUATION_BUDGET.  For example
+	// "timing-10m&(consecutive-50|target-40000)"
+	ret.Termination = os.Getenv("TERMINATION")
 	ret.Seed = mustOsInt64("RAND_SEED", 0)
 	if ret.Seed < 0 {
 		ret.Seed = time.Now().UnixNano()
 	}
+	// Set by AWS Batch for array jobs, so each job in the array gets its own random stream
+	ret.ArrayIndex = mustOsInt64("AWS_BATCH_JOB_ARRAY_INDEX", -1)
 	ret.Duration = mustOsDur("RUN_TIME", time.Minute)
 	ret.DynamoDBTable = os.Getenv("DYNAMODB_TABLE")
+	// Records runs locally, for sortanalyze results
+	ret.ResultsDir = os.Getenv("RESULTS_DIR")
+	// Records that DYNAMODB_TABLE or RESULTS_DIR still fail to take after RECORD_RETRIES are spooled to SPOOL_DIR, if
+	// set.  It should be durable storage, such as a mounted volume, since the job fails if a record is kept nowhere.
+	ret.SpoolDir = os.Getenv("SPOOL_DIR")
+	ret.RecordRetries = mustOsInt("RECORD_RETRIES", record.DefaultRetries)
+	ret.RecordBackoff = mustOsDur("RECORD_BACKOFF", time.Second)
+	ret.SortTarget = os.Getenv("SORT_TARGET")
+	ret.CostModel = os.Getenv("COST_MODEL")
+	ret.NumGoroutine = runtime.NumCPU()
+	// Allocation counts are process wide, so other goroutines sorting at the same time would be counted too
+	if ret.CostModel == "allocs" {
+		ret.NumGoroutine = 1
+	}
+	// Setting REFERENCE_TARGET scores by how much worse SORT_TARGET is than it.  DIFFERENTIAL is difference or ratio
+	ret.ReferenceTarget = os.Getenv("REFERENCE_TARGET")
+	ret.Differential = os.Getenv("DIFFERENTIAL")
+	if ret.Differential != "" && ret.Differential != "difference" && ret.Differential != "ratio" {
+		panic(fmt.Sprintf("unknown differential %q: valid values are difference or ratio", ret.Differential))
+	}
+	// A worker command, such as "sortworker -target go1.5/sort.Sort", overrides every other way of scoring arrays
+	ret.ExternalSort = strings.Fields(os.Getenv("EXTERNAL_SORT"))
+	ret.ExternalTimeout = mustOsDur("EXTERNAL_TIMEOUT", 10*time.Second)
+	ret.ExternalFailure = mustOsInt("EXTERNAL_FAILURE_COST", 0)
+	// Fraction of the first population built from SEED_PATTERNS, a comma separated list that defaults to every pattern
+	ret.SeedFraction = mustOsFloat("SEED_FRACTION", 0)
+	if patterns := os.Getenv("SEED_PATTERNS"); patterns != "" {
+		ret.SeedPatterns = strings.Split(patterns, ",")
+	}
+	// Shrink the best array to a small one that still has SHRINK_THRESHOLD comparisons/(n·log2 n), or by default the
+	// same growth between half its size and its size.  Without a threshold, arrays that don't grow faster than
+	// n·log2 n aren't shrunk.
+	ret.Shrink = mustOsBool("SHRINK", false)
+	ret.ShrinkThreshold = mustOsFloat("SHRINK_THRESHOLD", 0)
+	// CHROMOSOME is array, which evolves ARRAY_SIZE values directly, or generative, which evolves GENERATIVE_OPS long
+	// programs scored at each of GENERATIVE_SIZES.  The best program is then checked at each of VERIFY_SIZES.
+	// strings, records and floats evolve ARRAY_SIZE strings, stably sorted records or floats, and take SORT_TARGET and
+	// COST_MODEL from their own registries.  FLOAT_ORACLE checks every float sort and hunts for incorrect ones.
+	ret.Chromosome = os.Getenv("CHROMOSOME")
+	switch ret.Chromosome {
+	case "", "array", "generative":
+		if _, err := arraysort.NewTargetEvaluator(ret.SortTarget, ret.CostModel); err != nil {
+			panic(err)
+		}
+	case "strings":
+		if err := arraysort.CheckStringCost(ret.SortTarget, ret.CostModel); err != nil {
+			panic(err)
+		}
+	case "records":
+		if err := arraysort.CheckRecordCost(ret.SortTarget, ret.CostModel); err != nil {
+			panic(err)
+		}
+	case "floats":
+		if _, err := arraysort.LookupFloatSortTarget(ret.SortTarget); err != nil {
+			panic(err)
+		}
+	default:
+		panic(fmt.Sprintf("unknown chromosome %q: valid values are array, generative, strings, records or floats", ret.Chromosome))
+	}
+	if ret.Chromosome != "" && ret.Chromosome != "array" && ret.Shrink {
+		panic("SHRINK only works with array chromosomes")
+	}
+	if ret.Chromosome != "" && ret.Chromosome != "array" && ret.SeedFraction > 0 {
+		panic("SEED_FRACTION only works with array chromosomes")
+	}
+	if (ret.Chromosome == "strings" || ret.Chromosome == "records" || ret.Chromosome == "floats") && (ret.ReferenceTarget != "" || len(ret.ExternalSort) != 0) {
+		panic("REFERENCE_TARGET and EXTERNAL_SORT only work with array or generative chromosomes")
+	}
+	ret.FloatOracle = mustOsBool("FLOAT_ORACLE", false)
+	ret.SpecialRate = mustOsFloat("FLOAT_SPECIAL_RATE", 0)
+	ret.GenerativeOps = mustOsInt("GENERATIVE_OPS", 8)
+	ret.GenerativeSizes = mustOsInts("GENERATIVE_SIZES", nil)
+	ret.VerifySizes = mustOsInts("VERIFY_SIZES", []int{100000, 1000000})
+	return ret
+}
+
+// manifestConfig is every setting, after defaults, for the run manifest
+func (r runConfig) manifestConfig() map[string]string {
+	ret := make(map[string]string)
+	v := reflect.ValueOf(r)
+	for i := 0; i < v.NumField(); i++ {
+		ret[v.Type().Field(i).Name] = fmt.Sprint(v.Field(i).Interface())
+	}
 	return ret
 }
 
@@ -63,6 +193,46 @@ func mustOsInt64(s string, defaultVal int64) int64 {
 	return ret
 }
 
+func mustOsInts(s string, defaultVal []int) []int {
+	a := os.Getenv(s)
+	if a == "" {
+		return defaultVal
+	}
+	var ret []int
+	for _, field := range strings.Split(a, ",") {
+		i, err := strconv.Atoi(field)
+		if err != nil {
+			panic(err)
+		}
+		ret = append(ret, i)
+	}
+	return ret
+}
+
+func mustOsBool(s string, defaultVal bool) bool {
+	a := os.Getenv(s)
+	if a == "" {
+		return defaultVal
+	}
+	ret, err := strconv.ParseBool(a)
+	if err != nil {
+		panic(err)
+	}
+	return ret
+}
+
+func mustOsFloat(s string, defaultVal float64) float64 {
+	a := os.Getenv(s)
+	if a == "" {
+		return defaultVal
+	}
+	ret, err := strconv.ParseFloat(a, 64)
+	if err != nil {
+		panic(err)
+	}
+	return ret
+}
+
 // must_ie return i or panics if err.  the "ie" stands for "int/error"
 func mustOsDur(s string, defaultVal time.Duration) time.Duration {
 	a := os.Getenv(s)
@@ -76,62 +246,286 @@ func mustOsDur(s string, defaultVal time.Duration) time.Duration {
 	return ret
 }
 
-func main() {
-	conf := load()
-	a := genetic.Algorithm{
-		RandForIndex: genetic.ArrayRandForIdx(conf.PopulationSize, conf.Seed, func(seed int64) genetic.Rand {
-			return rand.New(rand.NewSource(seed))
-		}),
-		Log: log.New(os.Stdout, "", log.LstdFlags),
-		ParentSelector: &genetic.TournamentParentSelector{
-			K: conf.KTournament,
-		},
-		Factory: &arraysort.ArraySortingFactory{
-			// According to go stdlib TestAdversary
-			// - 100 is 1332
-			// - 500 is 13989
-			// - 1000 is 33454
+// verify checks an evolved program at sizes too large to evolve at
+func verify(conf runConfig, eval arraysort.Evaluator, generator arraysort.Generator, logger *log.Logger) {
+	if eval == nil {
+		var err error
+		if eval, err = arraysort.NewTargetEvaluator(conf.SortTarget, conf.CostModel); err != nil {
+			panic(err)
+		}
+	}
+	for _, n := range conf.VerifySizes {
+		cost := eval.Cost(generator.Generate(n))
+		logger.Printf("verify n=%d cost=%d intensity=%.3f", n, cost, arraysort.Intensity(cost, n))
+	}
+}
+
+func factory(conf runConfig, eval arraysort.Evaluator, logger *log.Logger) genetic.ChromosomeFactory {
+	switch conf.Chromosome {
+	case "floats":
+		return &arraysort.FloatSortingFactory{
 			IndividualSize: conf.ArraySize,
-		},
-		Terminator: &genetic.MultiTermination{
-			Executors: []genetic.Termination{
-				&genetic.TimingTermination{
-					Duration: conf.Duration,
-				},
-				&genetic.NoImprovementTermination{
-					Consecutive: conf.TerminationStall,
-				},
+			Target:         conf.SortTarget,
+			SpecialRate:    conf.SpecialRate,
+			Oracle:         conf.FloatOracle,
+			OnViolation: func(vals []float64, err error) {
+				logFinding(logger, "%s sorted incorrectly: %v: input=%v", conf.SortTarget, err, vals)
 			},
-		},
-		Crossover: &genetic.OnePointCrossover{},
-		SurvivorSelection: &genetic.ParentSurvivorSelection{
+		}
+	case "strings":
+		return &arraysort.StringSortingFactory{
+			IndividualSize: conf.ArraySize,
+			Target:         conf.SortTarget,
+			Cost:           conf.CostModel,
+		}
+	case "records":
+		return &arraysort.RecordSortingFactory{
+			IndividualSize: conf.ArraySize,
+			Target:         conf.SortTarget,
+			Cost:           conf.CostModel,
+		}
+	case "generative":
+		return &arraysort.GenerativeFactory{
+			Ops:       conf.GenerativeOps,
+			Sizes:     conf.GenerativeSizes,
+			Target:    conf.SortTarget,
+			Cost:      conf.CostModel,
+			Evaluator: eval,
+		}
+	}
+	f := &arraysort.ArraySortingFactory{
+		// According to go stdlib TestAdversary, against the go1.18/sort.Sort introsort
+		// - 100 is 1332
+		// - 500 is 13989
+		// - 1000 is 33454
+		IndividualSize: conf.ArraySize,
+		Target:         conf.SortTarget,
+		Cost:           conf.CostModel,
+		Evaluator:      eval,
+	}
+	if conf.SeedFraction <= 0 {
+		return f
+	}
+	return &arraysort.SeedingFactory{
+		Factory:  f,
+		Fraction: conf.SeedFraction,
+		Patterns: conf.SeedPatterns,
+	}
+}
+
+// maxFindings bounds how many bad inputs are logged.  Once the population converges on one, nearly every evaluation
+// finds it again.
+const maxFindings = 20
+
+var findings int32
+
+func logFinding(logger *log.Logger, format string, v ...interface{}) {
+	switch n := atomic.AddInt32(&findings, 1); {
+	case n < maxFindings:
+		logger.Printf(format, v...)
+	case n == maxFindings:
+		logger.Printf(format+" (no more findings will be logged)", v...)
+	}
+}
+
+// evaluator is nil unless an external or differential run is configured, leaving the factory to use SORT_TARGET and
+// COST_MODEL
+func evaluator(conf runConfig, logger *log.Logger) arraysort.Evaluator {
+	if len(conf.ExternalSort) != 0 {
+		return &arraysort.ExternalEvaluator{
+			Command:     conf.ExternalSort,
+			Workers:     runtime.NumCPU(),
+			Timeout:     conf.ExternalTimeout,
+			FailureCost: conf.ExternalFailure,
+			OnFailure: func(vals []int, err error) {
+				logFinding(logger, "external sort failed: input=%v err=%v", vals, err)
+			},
+		}
+	}
+	if conf.ReferenceTarget == "" {
+		return nil
+	}
+	eval, err := arraysort.NewDifferentialEvaluator(conf.SortTarget, conf.ReferenceTarget, conf.Differential == "ratio")
+	if err != nil {
+		panic(err)
+	}
+	eval.OnMismatch = func(vals []int, candidate []int, reference []int) {
+		logFinding(logger, "%s and %s sort differently: input=%v %s=%v %s=%v", eval.Candidate.Name(),
+			eval.Reference.Name(), vals, eval.Candidate.Name(), candidate, eval.Reference.Name(), reference)
+	}
+	return eval
+}
+
+func survivorSelection(conf runConfig) genetic.SurvivorSelection {
+	switch conf.Survivors {
+	case "", "parent":
+		return &genetic.ParentSurvivorSelection{
 			ParentSelector: &genetic.TournamentParentSelector{
 				K: conf.KTournament,
 			},
+		}
+	case "plus":
+		return &genetic.PlusSurvivorSelection{}
+	case "comma":
+		return &genetic.CommaSurvivorSelection{}
+	case "generational":
+		return &genetic.GenerationalSurvivorSelection{
+			Elitism: 1,
+		}
+	case "age":
+		return &genetic.AgeSurvivorSelection{
+			MaxAge: conf.MaxAge,
+		}
+	}
+	panic("unknown SURVIVOR_SELECTION " + conf.Survivors)
+}
+
+func terminator(conf runConfig) genetic.Termination {
+	if conf.Termination != "" {
+		ret, err := genetic.ParseTermination(conf.Termination)
+		if err != nil {
+			panic(err)
+		}
+		return ret
+	}
+	ret := &genetic.MultiTermination{
+		Executors: []genetic.Termination{
+			&genetic.TimingTermination{
+				Duration: conf.Duration,
+			},
+			&genetic.NoImprovementTermination{
+				Consecutive: conf.TerminationStall,
+			},
 		},
+	}
+	if conf.TargetFitness > 0 {
+		ret.Executors = append(ret.Executors, &genetic.TargetFitnessTermination{
+			Target: conf.TargetFitness,
+		})
+	}
+	if conf.EvaluationBudget > 0 {
+		ret.Executors = append(ret.Executors, &genetic.EvaluationBudgetTermination{
+			Budget: conf.EvaluationBudget,
+		})
+	}
+	return ret
+}
+
+func rootSource(conf runConfig) genetic.Source {
+	src := genetic.NewPCG(conf.Seed)
+	if conf.ArrayIndex < 0 {
+		return src
+	}
+	return src.Split(uint64(conf.ArrayIndex))
+}
+
+func main() {
+	conf := load()
+	logger := log.New(os.Stdout, "", log.LstdFlags)
+	eval := evaluator(conf, logger)
+	if closer, ok := eval.(io.Closer); ok {
+		defer func() {
+			if err := closer.Close(); err != nil {
+				logger.Println("unable to stop evaluator:", err)
+			}
+		}()
+	}
+	a := genetic.Algorithm{
+		RandForIndex: genetic.PhiloxRandForIndex(rootSource(conf).Uint64()),
+		Log:          logger,
+		ParentSelector: &genetic.TournamentParentSelector{
+			K: conf.KTournament,
+		},
+		Factory:           factory(conf, eval, logger),
+		Terminator:        terminator(conf),
+		Crossover:         &genetic.OnePointCrossover{},
+		SurvivorSelection: survivorSelection(conf),
 		Mutator: &genetic.PassThruDynamicMutation{
 			MutationRatio: conf.MutationRation,
 			PassTo:        &genetic.IndexMutation{},
 		},
 		NumberOfParents: 2,
 		PopulationSize:  conf.PopulationSize,
-		NumGoroutine:    runtime.NumCPU(),
+		OffspringSize:   conf.OffspringSize,
+		NumGoroutine:    conf.NumGoroutine,
+		Manifest: genetic.Manifest{
+			Seed:   conf.Seed,
+			Config: conf.manifestConfig(),
+			// Set by make.sh, since docker builds don't have the vcs information go build records
+			Commit: os.Getenv("GIT_COMMIT"),
+		},
 	}
 	fittest := a.Run()
-	fittest.(genetic.Simplifyable).Simplify()
+	if asSimpl, canSimpl := fittest.(genetic.Simplifyable); canSimpl {
+		asSimpl.Simplify()
+	}
 	fmt.Println(fittest)
+	// Measured once, for the log and every sink, since wall time and allocations differ each time
+	var metrics map[string]int
+	if asMeasurable, ok := fittest.(genetic.Measurable); ok {
+		metrics = asMeasurable.Metrics()
+		a.Log.Println("metrics", metrics)
+	}
+	a.Log.Printf("generations=%d evaluations=%d elapsed=%s seed=%d commit=%s", a.Stats.Generation, a.Stats.Evaluations, a.Manifest.WallTime(), a.Manifest.Seed, a.Manifest.Commit)
+	if generator, ok := fittest.(arraysort.Generator); ok {
+		verify(conf, eval, generator, a.Log)
+	}
+	if conf.Shrink {
+		shrunk := (&arraysort.Shrinker{Threshold: conf.ShrinkThreshold}).ShrinkChromosome(fittest)
+		a.Log.Printf("shrunk from %d to %d values", fittest.(genetic.Array).Len(), shrunk.(genetic.Array).Len())
+		fmt.Println(shrunk)
+	}
+	sinks := recorders(conf)
+	if len(sinks) == 0 {
+		return
+	}
+	rec := &record.FanOut{
+		Sinks:   sinks,
+		Retries: conf.RecordRetries,
+		Backoff: conf.RecordBackoff,
+		Log:     a.Log,
+	}
+	if conf.SpoolDir != "" {
+		rec.Spool = &filerecord.Recorder{
+			Dir: conf.SpoolDir,
+		}
+	}
+	err := rec.Record(context.Background(), record.Record{
+		Algorithm:     a,
+		BestCandidate: fittest,
+		Manifest:      a.Manifest,
+		Metrics:       metrics,
+	})
+	var asFanOut *record.FanOutError
+	if errors.As(err, &asFanOut) && len(asFanOut.Succeeded) == 0 && !asFanOut.Spooled {
+		// Nothing kept the record, so fail the job rather than lose the result
+		a.Log.Fatalln("unable to record anywhere:", err)
+	}
+	// Losing some sinks shouldn't fail the job: the others, or the spool, have the record
+	if err != nil {
+		a.Log.Println("unable to record:", err)
+	}
+}
+
+func recorders(conf runConfig) []record.Sink {
+	var ret []record.Sink
 	if conf.DynamoDBTable != "" {
 		ses := session.Must(session.NewSession())
-		ddb := dynamodb.New(ses)
-		drec := &dynamorecord.Recorder{
-			Client:    ddb,
-			TableName: conf.DynamoDBTable,
-		}
-		if err := drec.Record(context.Background(), record.Record{
-			Algorithm:     a,
-			BestCandidate: fittest,
-		}); err != nil {
-			panic(err)
-		}
+		ret = append(ret, record.Sink{
+			Name: "dynamodb:" + conf.DynamoDBTable,
+			Recorder: &dynamorecord.Recorder{
+				Client:    dynamodb.New(ses),
+				TableName: conf.DynamoDBTable,
+			},
+		})
+	}
+	if conf.ResultsDir != "" {
+		ret = append(ret, record.Sink{
+			Name: "file:" + conf.ResultsDir,
+			Recorder: &filerecord.Recorder{
+				Dir: conf.ResultsDir,
+			},
+		})
 	}
+	return ret
 }
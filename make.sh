@@ -49,7 +49,7 @@ function verify_cli() {
 
 function docker_push() {
     REGION=$(get_aws_region)
-    docker build -t $(stack_output ImageName) .
+    docker build --build-arg GIT_COMMIT=${GIT_COMMIT} -t $(stack_output ImageName) .
     $(aws ecr get-login --no-include-email --region ${REGION})
     docker push $(stack_output ImageName)
 }